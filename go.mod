@@ -7,6 +7,7 @@ require (
 	github.com/ohler55/ojg v1.14.5
 	github.com/rivo/tview v0.0.0-20221128165837-db36428c92d9
 	github.com/urfave/cli/v2 v2.23.6
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,229 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"tacher/src/catalog"
+	"tacher/src/client"
+	"tacher/src/errs"
+	"tacher/src/generator"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"tacher/src/policy"
+	"tacher/src/utils"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+const STATUS_GENERATED = "generated"
+const STATUS_FAILED = "failed"
+const STATUS_SKIPPED = "skipped"
+
+// options of a batch generation, set from the command line
+type Options struct {
+	// number of projects generated concurrently
+	Workers int
+	// maximum number of requests per second sent to each host, zero means no limit
+	RateLimit float64
+	// keep generating the remaining projects after a failure
+	ContinueOnError bool
+	// local catalogs of dependencies merged with Spring initializer's ones
+	Catalogs []string
+	// options applied to the generation of each project
+	Generation generator.Options
+	// rules that every project must follow
+	Policy *policy.Policy
+}
+
+// manifest file listing the projects to generate
+type manifest struct {
+	Projects []entry `yaml:"projects"`
+}
+
+// project described in the manifest, fields that are not set take Spring initializer's defaults
+type entry struct {
+	Group             string                       `yaml:"group"`
+	Artifact          string                       `yaml:"artifact"`
+	Name              string                       `yaml:"name"`
+	Description       string                       `yaml:"description"`
+	Pkg               string                       `yaml:"package"`
+	SpringBuildTool   string                       `yaml:"type"`
+	Language          string                       `yaml:"language"`
+	JavaVersion       string                       `yaml:"javaVersion"`
+	SpringBootVersion string                       `yaml:"bootVersion"`
+	Packaging         string                       `yaml:"packaging"`
+	Dependencies      []string                     `yaml:"dependencies"`
+	Modules           []generator.ModuleDefinition `yaml:"modules"`
+	Path              string                       `yaml:"path"`
+}
+
+// outcome of the generation of a project
+type result struct {
+	data   *model.AppData
+	status string
+	err    error
+}
+
+// generate the projects listed in the manifest file and print a summary of the results
+func Run(manifestFile string, opts Options, out io.Writer) error {
+	content, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return err
+	}
+	var m manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return errs.Input("can't parse %s: %w", manifestFile, err)
+	}
+	if len(m.Projects) == 0 {
+		return errs.Input("no projects found in %s", manifestFile)
+	}
+
+	state := new(model.AppState)
+	if err := client.GetOptions(state); err != nil {
+		return err
+	}
+	if err := catalog.Merge(opts.Catalogs, state); err != nil {
+		return err
+	}
+	client.SetRateLimit(opts.RateLimit)
+
+	// invalid entries fail before anything is generated, like the ones sharing the directory of a
+	// previous entry, the workers would write the same files
+	results := make([]result, len(m.Projects))
+	targets := make(map[string]int)
+	for i, e := range m.Projects {
+		results[i].data, results[i].err = e.toAppData(state)
+		if results[i].err == nil {
+			target := filepath.Join(results[i].data.Path, results[i].data.Artifact)
+			if first, found := targets[target]; found {
+				results[i].err = errs.Input("%s is already the directory of project #%d", target, first+1)
+			} else {
+				targets[target] = i
+			}
+		}
+		if results[i].err == nil && opts.Policy != nil {
+			results[i].err = opts.Policy.Check(results[i].data)
+		}
+		if results[i].err != nil {
+			results[i].status = STATUS_FAILED
+		}
+	}
+
+	// generate the projects with a bounded pool of workers
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	failed := false
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mutex.Lock()
+				stop := failed && !opts.ContinueOnError
+				mutex.Unlock()
+				if stop {
+					results[i].status = STATUS_SKIPPED
+					continue
+				}
+				logging.Info("batch project started", "project", i+1, "artifact", results[i].data.Artifact)
+				if err := generator.Generate(results[i].data, opts.Generation); err != nil {
+					results[i].status, results[i].err = STATUS_FAILED, err
+					mutex.Lock()
+					failed = true
+					mutex.Unlock()
+				} else {
+					results[i].status = STATUS_GENERATED
+				}
+			}
+		}()
+	}
+	for i := range results {
+		if results[i].status == STATUS_FAILED {
+			mutex.Lock()
+			failed = true
+			mutex.Unlock()
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return printResults(results, out)
+}
+
+// print a table with the result of each project, returns an error if any project failed. Its exit
+// code is the one of the failures if they share it
+func printResults(results []result, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tPATH\tSTATUS\tERROR")
+	failures := &errs.BatchError{Total: len(results)}
+	for i, r := range results {
+		project, path, message := fmt.Sprintf("#%d", i+1), "", ""
+		if r.data != nil {
+			project = r.data.Artifact
+			path = filepath.Join(r.data.Path, r.data.Artifact)
+		}
+		var violations *errs.ViolationError
+		if errors.As(r.err, &violations) {
+			// one line per project
+			message = "policy violations: " + strings.Join(violations.Violations, "; ")
+		} else if r.err != nil {
+			message = r.err.Error()
+		}
+		if r.status != STATUS_GENERATED {
+			failures.Failed++
+		}
+		if r.err != nil {
+			failures.Errs = append(failures.Errs, r.err)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", project, path, r.status, message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failures.Failed > 0 {
+		return failures
+	}
+	return nil
+}
+
+// fill the project's data, using Spring initializer's defaults for the missing fields
+func (e entry) toAppData(state *model.AppState) (*model.AppData, error) {
+	data := &model.AppData{
+		Group:             utils.NonNullOrElse(e.Group, state.DefaultGroupId),
+		Artifact:          utils.NonNullOrElse(e.Artifact, state.DefaultArtifactId),
+		Name:              utils.NonNullOrElse(e.Name, utils.NonNullOrElse(e.Artifact, state.DefaultName)),
+		Description:       utils.NonNullOrElse(e.Description, state.DefaultDescription),
+		Pkg:               utils.NonNullOrElse(e.Pkg, state.DefaultPackageName),
+		SpringBuildTool:   utils.NonNullOrElse(e.SpringBuildTool, state.SpringBuildTools[state.DefaultSpringBuildTool].ID),
+		Language:          utils.NonNullOrElse(e.Language, state.Languages[state.DefaultLanguage].ID),
+		JavaVersion:       utils.NonNullOrElse(e.JavaVersion, state.JavaVersions[state.DefaultJavaVersion].ID),
+		SpringBootVersion: utils.NonNullOrElse(e.SpringBootVersion, state.SpringVersions[state.DefaultSpringVersion].ID),
+		Packaging:         utils.NonNullOrElse(e.Packaging, state.Packaging[state.DefaultPackaging].ID),
+		Path:              utils.NonNullOrElse(e.Path, "."),
+	}
+
+	var err error
+	if data.Path, err = filepath.Abs(data.Path); err != nil {
+		return data, err
+	}
+	if data.Dependencies, err = generator.ResolveDependencies(e.Dependencies, state); err != nil {
+		return data, err
+	}
+	if data.Modules, err = generator.ToModules(e.Modules, state); err != nil {
+		return data, err
+	}
+	return data, nil
+}
@@ -0,0 +1,212 @@
+package buildfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"tacher/src/i18n"
+	"tacher/src/logging"
+	"tacher/src/model"
+)
+
+const MAVEN_POM = "pom.xml"
+const GRADLE_BUILD = "build.gradle"
+const GRADLE_BUILD_KTS = "build.gradle.kts"
+
+const BUILD_MAVEN = "maven"
+const BUILD_GRADLE = "gradle"
+const BUILD_GRADLE_KOTLIN = "gradle-kotlin"
+
+// dependencies that are part of every generated project, they don't have to be mapped
+var implicitDependencies = map[string]bool{
+	"org.springframework.boot:spring-boot-starter":      true,
+	"org.springframework.boot:spring-boot-starter-test": true,
+	"io.projectreactor:reactor-test":                    true,
+	"org.jetbrains.kotlin:kotlin-reflect":               true,
+	"org.jetbrains.kotlin:kotlin-stdlib":                true,
+	"org.jetbrains.kotlin:kotlin-stdlib-jdk8":           true,
+	"org.jetbrains.kotlin:kotlin-test-junit5":           true,
+	"org.apache.groovy:groovy":                          true,
+	"org.codehaus.groovy:groovy":                        true,
+	"org.junit.platform:junit-platform-launcher":        true,
+}
+
+// settings read from an existing project's build file
+type Project struct {
+	Group        string
+	Artifact     string
+	Version      string
+	Name         string
+	Description  string
+	BuildTool    string
+	Language     string
+	BootVersion  string
+	JavaVersion  string
+	Packaging    string
+	Dependencies []model.Coordinates
+}
+
+// parse the build file found in the given project directory
+func Parse(dir string) (*Project, error) {
+	if content, err := os.ReadFile(filepath.Join(dir, MAVEN_POM)); err == nil {
+		return parseMaven(content)
+	}
+	for _, name := range []string{GRADLE_BUILD, GRADLE_BUILD_KTS} {
+		if content, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			settings, _ := readGradleSettings(dir)
+			return parseGradle(content, settings, name == GRADLE_BUILD_KTS)
+		}
+	}
+	return nil, fmt.Errorf("no %s, %s or %s found in %s", MAVEN_POM, GRADLE_BUILD, GRADLE_BUILD_KTS, dir)
+}
+
+// gets the coordinates of the server's dependencies by ID for a Spring Boot version, the default
+// one if it's empty
+type CoordinatesFunc func(bootVersion string) (map[string]model.Coordinates, error)
+
+// map the project's settings on the options offered by Spring initializer. Dependencies are
+// mapped by their coordinates, the server's ones are got for the project's Spring Boot version.
+// Without them, the starters are still mapped by their name. Returns the data to pre-fill the
+// forms with and a description of each setting that couldn't be mapped
+func (p *Project) ToAppData(state *model.AppState, coordinatesOf CoordinatesFunc) (*model.AppData, []string) {
+	data := new(model.AppData)
+	unmapped := make([]string, 0)
+
+	data.Group = p.Group
+	data.Artifact = p.Artifact
+	data.Name = p.Name
+	data.Description = p.Description
+	if p.Group != "" && p.Artifact != "" {
+		data.Pkg = p.Group + "." + strings.ReplaceAll(p.Artifact, "-", "")
+	}
+
+	// map single-choice options on the values offered by the server
+	if p.BuildTool != "" {
+		if tool, found := findBuildTool(state.SpringBuildTools, p.BuildTool); found {
+			data.SpringBuildTool = tool
+		} else {
+			unmapped = append(unmapped, i18n.T("unmapped.build_tool", p.BuildTool))
+		}
+	}
+	if p.Language != "" {
+		if language, found := findValue(state.Languages, p.Language); found {
+			data.Language = language
+		} else {
+			unmapped = append(unmapped, i18n.T("unmapped.language", p.Language))
+		}
+	}
+	if p.BootVersion != "" {
+		if version, found := findValue(state.SpringVersions, normalizeBootVersion(p.BootVersion)); found {
+			data.SpringBootVersion = version
+		} else {
+			unmapped = append(unmapped, i18n.T("unmapped.boot", p.BootVersion))
+		}
+	}
+	if p.JavaVersion != "" {
+		if version, found := findValue(state.JavaVersions, normalizeJavaVersion(p.JavaVersion)); found {
+			data.JavaVersion = version
+		} else {
+			unmapped = append(unmapped, i18n.T("unmapped.java", p.JavaVersion))
+		}
+	}
+	if p.Packaging != "" {
+		if packaging, found := findValue(state.Packaging, p.Packaging); found {
+			data.Packaging = packaging
+		} else {
+			unmapped = append(unmapped, i18n.T("unmapped.packaging", p.Packaging))
+		}
+	}
+
+	if len(p.Dependencies) == 0 {
+		return data, unmapped
+	}
+	coordinates, err := coordinatesOf(data.SpringBootVersion)
+	if err != nil {
+		logging.Warn("can't get the coordinates of the dependencies, only the starters are mapped", "error", err)
+	}
+
+	// index the dependencies offered by the server by ID and by coordinates, the custom ones
+	// have their own coordinates
+	byID := make(map[string]model.ValueWithDesc)
+	byCoordinates := make(map[string]string)
+	for _, deps := range state.Dependency {
+		for _, d := range deps {
+			byID[d.ID] = d
+			c, found := coordinates[d.ID]
+			if d.Custom != nil {
+				c, found = d.Custom.Coordinates, true
+			}
+			if found {
+				byCoordinates[c.GroupId+":"+c.ArtifactId] = d.ID
+			}
+		}
+	}
+
+	// map dependencies on Spring initializer's dependencies IDs
+	added := make(map[string]bool)
+	for _, d := range p.Dependencies {
+		key := d.GroupId + ":" + d.ArtifactId
+		if implicitDependencies[key] {
+			continue
+		}
+		id, found := byCoordinates[key]
+		if !found && d.GroupId == "org.springframework.boot" && strings.HasPrefix(d.ArtifactId, "spring-boot-starter-") {
+			// no coordinates from the server, guess the ID from the starter's name
+			id, found = strings.TrimPrefix(d.ArtifactId, "spring-boot-starter-"), true
+		}
+		dep, offered := byID[id]
+		if !found || !offered {
+			if d.Scope != "test" {
+				unmapped = append(unmapped, i18n.T("unmapped.dependency", key))
+			}
+			continue
+		}
+		if !added[id] {
+			added[id] = true
+			data.Dependencies = append(data.Dependencies, dep)
+		}
+	}
+
+	return data, unmapped
+}
+
+// find the build tool with the given build system, e.g. 'maven' matches 'maven-project'. Gradle's
+// Kotlin DSL falls back to the Groovy DSL if the server doesn't offer it
+func findBuildTool(tools []model.ValueWithDesc, build string) (string, bool) {
+	candidates := []string{build, build + "-project"}
+	if build == BUILD_GRADLE_KOTLIN {
+		candidates = append(candidates, "gradle-project-kotlin", "gradle-project")
+	}
+	for _, c := range candidates {
+		for _, t := range tools {
+			if t.ID == c {
+				return t.ID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// find the value whose ID matches the given one
+func findValue(values []model.Value, id string) (string, bool) {
+	for _, v := range values {
+		if v.ID == id || normalizeBootVersion(v.ID) == id {
+			return v.ID, true
+		}
+	}
+	return "", false
+}
+
+// remove the old '.RELEASE' suffix from Spring Boot versions
+func normalizeBootVersion(version string) string {
+	return strings.TrimSuffix(version, ".RELEASE")
+}
+
+// Java 8 is offered as '1.8' by Spring initializer
+func normalizeJavaVersion(version string) string {
+	if version == "8" {
+		return "1.8"
+	}
+	return version
+}
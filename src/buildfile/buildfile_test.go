@@ -0,0 +1,185 @@
+package buildfile
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"tacher/src/i18n"
+	"tacher/src/model"
+	"tacher/src/utils"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want Project
+	}{
+		{"maven-parent", Project{
+			Group:       "com.example",
+			Artifact:    "demo",
+			Version:     "0.0.1-SNAPSHOT",
+			Name:        "demo",
+			Description: "Demo project for Spring Boot",
+			BuildTool:   BUILD_MAVEN,
+			Language:    "java",
+			BootVersion: "3.0.1",
+			JavaVersion: "17",
+			Packaging:   "war",
+			Dependencies: []model.Coordinates{
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-data-jpa"},
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-web"},
+				{GroupId: "org.postgresql", ArtifactId: "postgresql", Scope: "runtime"},
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-tomcat", Scope: "provided"},
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-test", Scope: "test"},
+			},
+		}},
+		// the group comes from a company parent and the Spring Boot version from the imported BOM
+		{"maven-bom", Project{
+			Group:       "com.acme.platform",
+			Artifact:    "billing-service",
+			Version:     "1.4.0-SNAPSHOT",
+			Name:        "Billing service",
+			BuildTool:   BUILD_MAVEN,
+			Language:    "kotlin",
+			BootVersion: "2.7.7",
+			JavaVersion: "11",
+			Dependencies: []model.Coordinates{
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-webflux"},
+				{GroupId: "org.jetbrains.kotlin", ArtifactId: "kotlin-reflect"},
+				{GroupId: "com.acme", ArtifactId: "acme-audit", Version: "3.2.0"},
+				{GroupId: "io.projectreactor", ArtifactId: "reactor-test", Scope: "test"},
+			},
+		}},
+		{"gradle", Project{
+			Group:       "com.example",
+			Artifact:    "demo",
+			Version:     "0.0.1-SNAPSHOT",
+			Name:        "demo",
+			BuildTool:   BUILD_GRADLE,
+			Language:    "java",
+			BootVersion: "3.0.1",
+			JavaVersion: "17",
+			Packaging:   "war",
+			Dependencies: []model.Coordinates{
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-web", Scope: "compile"},
+				{GroupId: "org.projectlombok", ArtifactId: "lombok", Scope: "provided"},
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-devtools", Scope: "runtime"},
+				{GroupId: "org.postgresql", ArtifactId: "postgresql", Scope: "runtime"},
+				{GroupId: "org.projectlombok", ArtifactId: "lombok", Scope: "annotationProcessor"},
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-tomcat", Scope: "provided"},
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-test", Scope: "test"},
+			},
+		}},
+		{"gradle-kotlin", Project{
+			Group:       "com.example",
+			Artifact:    "demo",
+			Version:     "0.0.1-SNAPSHOT",
+			Name:        "demo",
+			BuildTool:   BUILD_GRADLE_KOTLIN,
+			Language:    "kotlin",
+			BootVersion: "3.0.1",
+			JavaVersion: "17",
+			Packaging:   "jar",
+			Dependencies: []model.Coordinates{
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-actuator", Scope: "compile"},
+				{GroupId: "com.fasterxml.jackson.module", ArtifactId: "jackson-module-kotlin", Scope: "compile"},
+				{GroupId: "org.jetbrains.kotlin", ArtifactId: "kotlin-reflect", Scope: "compile"},
+				{GroupId: "org.jetbrains.kotlin", ArtifactId: "kotlin-stdlib-jdk8", Scope: "compile"},
+				{GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-test", Scope: "test"},
+			},
+		}},
+	}
+	for _, test := range tests {
+		prj, err := Parse(filepath.Join("testdata", test.dir))
+		if err != nil {
+			t.Errorf("Parse(%s) failed: %v", test.dir, err)
+		} else if !reflect.DeepEqual(*prj, test.want) {
+			t.Errorf("Parse(%s) = %+v, want %+v", test.dir, *prj, test.want)
+		}
+	}
+}
+
+func TestParseWithoutBuildFile(t *testing.T) {
+	if _, err := Parse(t.TempDir()); err == nil {
+		t.Error("Parse() succeeded without a build file, want an error")
+	}
+}
+
+func TestToAppData(t *testing.T) {
+	state := &model.AppState{
+		SpringBuildTools: []model.ValueWithDesc{{ID: "maven-project"}, {ID: "gradle-project"}},
+		Languages:        []model.Value{{ID: "java"}, {ID: "kotlin"}},
+		SpringVersions:   []model.Value{{ID: "3.0.1"}, {ID: "2.7.7"}},
+		JavaVersions:     []model.Value{{ID: "17"}, {ID: "11"}},
+		Packaging:        []model.Value{{ID: "jar"}, {ID: "war"}},
+		Dependency: map[string][]model.ValueWithDesc{
+			"Web": {{ID: "web"}},
+			"SQL": {{ID: "data-jpa"}, {ID: "postgresql"}},
+		},
+	}
+	coordinates := map[string]model.Coordinates{
+		"web":        {GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-web"},
+		"data-jpa":   {GroupId: "org.springframework.boot", ArtifactId: "spring-boot-starter-data-jpa"},
+		"postgresql": {GroupId: "org.postgresql", ArtifactId: "postgresql"},
+	}
+	prj, err := Parse(filepath.Join("testdata", "maven-parent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		coordinatesOf CoordinatesFunc
+		dependencies  []string
+		unmapped      []string
+	}{
+		{
+			"with the coordinates",
+			func(bootVersion string) (map[string]model.Coordinates, error) { return coordinates, nil },
+			[]string{"data-jpa", "web", "postgresql"},
+			[]string{"dependency org.springframework.boot:spring-boot-starter-tomcat"},
+		},
+		{
+			// the starters are still mapped by their name
+			"without the coordinates",
+			func(bootVersion string) (map[string]model.Coordinates, error) { return nil, errors.New("offline") },
+			[]string{"data-jpa", "web"},
+			[]string{"dependency org.postgresql:postgresql", "dependency org.springframework.boot:spring-boot-starter-tomcat"},
+		},
+	}
+	for _, test := range tests {
+		data, unmapped := prj.ToAppData(state, test.coordinatesOf)
+		if data.Group != "com.example" || data.SpringBuildTool != "maven-project" || data.SpringBootVersion != "3.0.1" ||
+			data.JavaVersion != "17" || data.Packaging != "war" || data.Pkg != "com.example.demo" {
+			t.Errorf("%s: ToAppData() = %+v, want the project's settings", test.name, data)
+		}
+		ids := make([]string, 0)
+		for _, d := range data.Dependencies {
+			ids = append(ids, d.ID)
+		}
+		if !reflect.DeepEqual(ids, test.dependencies) {
+			t.Errorf("%s: dependencies = %q, want %q", test.name, ids, test.dependencies)
+		}
+		if !reflect.DeepEqual(unmapped, test.unmapped) {
+			t.Errorf("%s: unmapped = %q, want %q", test.name, unmapped, test.unmapped)
+		}
+	}
+}
+
+func TestToAppDataInFrench(t *testing.T) {
+	i18n.SetLanguage("fr")
+	defer i18n.SetLanguage("en")
+	// the server offers none of the project's settings
+	state := &model.AppState{JavaVersions: []model.Value{{ID: "21"}}, Packaging: []model.Value{{ID: "jar"}}}
+	prj, err := Parse(filepath.Join("testdata", "maven-parent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, unmapped := prj.ToAppData(state, func(string) (map[string]model.Coordinates, error) { return nil, nil })
+	for _, want := range []string{"version de Java 17", "packaging war", "dépendance org.postgresql:postgresql"} {
+		if _, found := utils.Find(unmapped, func(u string) bool { return u == want }); !found {
+			t.Errorf("unmapped = %q, want %q among them", unmapped, want)
+		}
+	}
+}
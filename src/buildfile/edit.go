@@ -0,0 +1,198 @@
+package buildfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"tacher/src/model"
+)
+
+// gradle configuration of each scope
+var scopeConfigurations = map[string]string{
+	"compile":             "implementation",
+	"runtime":             "runtimeOnly",
+	"provided":            "compileOnly",
+	"test":                "testImplementation",
+	"annotationProcessor": "annotationProcessor",
+}
+
+// add the given dependencies and their BOMs to the build file of the project in the given directory
+func AddDependencies(dir string, deps []model.CustomDependency) error {
+	if len(deps) == 0 {
+		return nil
+	}
+	for _, name := range []string{MAVEN_POM, GRADLE_BUILD, GRADLE_BUILD_KTS} {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		build, err := AddDependenciesTo(name, string(content), deps)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(build), 0644)
+	}
+	return fmt.Errorf("no build file found in %s", dir)
+}
+
+// add the given dependencies and their BOMs to the content of a build file, named like the
+// build files of the projects
+func AddDependenciesTo(name string, build string, deps []model.CustomDependency) (string, error) {
+	var err error
+	for _, d := range deps {
+		switch name {
+		case MAVEN_POM:
+			build, err = addMavenDependency(build, d)
+		default:
+			build, err = addGradleDependency(build, d, name == GRADLE_BUILD_KTS)
+		}
+		if err != nil {
+			return build, fmt.Errorf("can't add %s:%s to %s: %w", d.Coordinates.GroupId, d.Coordinates.ArtifactId, name, err)
+		}
+	}
+	return build, nil
+}
+
+// add a dependency to a pom generated by Spring initializer
+func addMavenDependency(pom string, d model.CustomDependency) (string, error) {
+	c := d.Coordinates
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\t\t<dependency>\n\t\t\t<groupId>%s</groupId>\n\t\t\t<artifactId>%s</artifactId>", c.GroupId, c.ArtifactId)
+	if c.Version != "" {
+		fmt.Fprintf(&b, "\n\t\t\t<version>%s</version>", c.Version)
+	}
+	switch c.Scope {
+	case "", "compile":
+	case "annotationProcessor":
+		b.WriteString("\n\t\t\t<optional>true</optional>")
+	default:
+		fmt.Fprintf(&b, "\n\t\t\t<scope>%s</scope>", c.Scope)
+	}
+	b.WriteString("\n\t\t</dependency>")
+
+	end := strings.Index(pom, "\n\t</dependencies>")
+	if end < 0 {
+		return pom, fmt.Errorf("no dependencies section found")
+	}
+	pom = pom[:end] + b.String() + pom[end:]
+	for _, r := range d.Repositories {
+		pom = addMavenRepository(pom, r)
+	}
+
+	if d.Bom == nil || strings.Contains(pom, "<artifactId>"+d.Bom.ArtifactId+"</artifactId>") {
+		return pom, nil
+	}
+	bom := fmt.Sprintf("\n\t\t\t<dependency>\n\t\t\t\t<groupId>%s</groupId>\n\t\t\t\t<artifactId>%s</artifactId>\n\t\t\t\t<version>%s</version>\n\t\t\t\t<type>pom</type>\n\t\t\t\t<scope>import</scope>\n\t\t\t</dependency>",
+		d.Bom.GroupId, d.Bom.ArtifactId, d.Bom.Version)
+	if end := strings.Index(pom, "\n\t\t</dependencies>\n\t</dependencyManagement>"); end >= 0 {
+		return pom[:end] + bom + pom[end:], nil
+	}
+	end = strings.Index(pom, "\n\t</dependencies>") + len("\n\t</dependencies>")
+	return pom[:end] + "\n\t<dependencyManagement>\n\t\t<dependencies>" + bom + "\n\t\t</dependencies>\n\t</dependencyManagement>" + pom[end:], nil
+}
+
+// add a repository to a pom generated by Spring initializer, unless it's already declared
+func addMavenRepository(pom string, r model.Repository) string {
+	if strings.Contains(pom, "<url>"+r.URL+"</url>") {
+		return pom
+	}
+	repository := fmt.Sprintf("\n\t\t<repository>\n\t\t\t<id>%s</id>", r.ID)
+	if r.Name != "" {
+		repository += fmt.Sprintf("\n\t\t\t<name>%s</name>", r.Name)
+	}
+	repository += fmt.Sprintf("\n\t\t\t<url>%s</url>\n\t\t</repository>", r.URL)
+	if end := strings.Index(pom, "\n\t</repositories>"); end >= 0 {
+		return pom[:end] + repository + pom[end:]
+	}
+	end := strings.LastIndex(pom, "\n</project>")
+	if end < 0 {
+		end = len(pom)
+	}
+	return pom[:end] + "\n\t<repositories>" + repository + "\n\t</repositories>" + pom[end:]
+}
+
+// add a dependency to a build.gradle(.kts) generated by Spring initializer
+func addGradleDependency(build string, d model.CustomDependency, kotlinDsl bool) (string, error) {
+	c := d.Coordinates
+	notation := c.GroupId + ":" + c.ArtifactId
+	if c.Version != "" {
+		notation += ":" + c.Version
+	}
+	configuration, found := scopeConfigurations[c.Scope]
+	if !found {
+		configuration = "implementation"
+	}
+	line := fmt.Sprintf("\n\t%s '%s'", configuration, notation)
+	if kotlinDsl {
+		line = fmt.Sprintf("\n\t%s(\"%s\")", configuration, notation)
+	}
+
+	_, closing, found := findBlock(build, "dependencies")
+	if !found {
+		return build, fmt.Errorf("no dependencies block found")
+	}
+	build = build[:closing] + line + build[closing:]
+	for _, r := range d.Repositories {
+		build = addGradleRepository(build, r, kotlinDsl)
+	}
+
+	if d.Bom == nil || strings.Contains(build, ":"+d.Bom.ArtifactId+":") {
+		return build, nil
+	}
+	bom := fmt.Sprintf("\n\t\tmavenBom \"%s:%s:%s\"", d.Bom.GroupId, d.Bom.ArtifactId, d.Bom.Version)
+	if kotlinDsl {
+		bom = fmt.Sprintf("\n\t\tmavenBom(\"%s:%s:%s\")", d.Bom.GroupId, d.Bom.ArtifactId, d.Bom.Version)
+	}
+	if start, _, found := findBlock(build, "dependencyManagement"); found {
+		// the imports block is nested in the dependency management block
+		if _, closing, found := findBlock(build[start:], "\timports"); found {
+			return build[:start+closing] + bom + build[start+closing:], nil
+		}
+	}
+	// add the dependency management block after the dependencies block
+	_, closing, _ = findBlock(build, "dependencies")
+	end := closing + len("\n}")
+	return build[:end] + "\n\ndependencyManagement {\n\timports {" + bom + "\n\t}\n}" + build[end:], nil
+}
+
+// add a repository to the repositories block of a build.gradle(.kts), unless it's already declared
+func addGradleRepository(build string, r model.Repository, kotlinDsl bool) string {
+	if strings.Contains(build, "'"+r.URL+"'") || strings.Contains(build, `"`+r.URL+`"`) {
+		return build
+	}
+	line := fmt.Sprintf("\n\tmaven { url '%s' }", r.URL)
+	if kotlinDsl {
+		line = fmt.Sprintf("\n\tmaven { url = uri(\"%s\") }", r.URL)
+	}
+	if _, closing, found := findBlock(build, "repositories"); found {
+		return build[:closing] + line + build[closing:]
+	}
+	// the dependencies block follows the repositories one
+	start, _, _ := findBlock(build, "dependencies")
+	return build[:start] + "repositories {" + line + "\n}\n\n" + build[start:]
+}
+
+// find the block starting at the beginning of a line with the given name. Returns the index
+// where the block starts and the index of the line break before the line of its closing brace
+func findBlock(text string, name string) (int, int, bool) {
+	start := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(name) + `\s*\{`).FindStringIndex(text)
+	if start == nil {
+		return 0, 0, false
+	}
+	depth := 0
+	for i := start[1] - 1; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return start[0], strings.LastIndex(text[:i], "\n"), true
+			}
+		}
+	}
+	return 0, 0, false
+}
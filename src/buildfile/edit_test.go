@@ -0,0 +1,111 @@
+package buildfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"tacher/src/model"
+	"testing"
+)
+
+const mavenPom = `<project>
+	<dependencies>
+		<dependency>
+			<groupId>org.springframework.boot</groupId>
+			<artifactId>spring-boot-starter</artifactId>
+		</dependency>
+	</dependencies>
+</project>
+`
+
+const gradleBuild = `plugins {
+	id 'java'
+}
+
+repositories {
+	mavenCentral()
+}
+
+dependencies {
+	implementation 'org.springframework.boot:spring-boot-starter'
+}
+`
+
+var customDependency = model.CustomDependency{
+	Coordinates:  model.Coordinates{GroupId: "com.acme", ArtifactId: "acme-client", Scope: "runtime"},
+	Bom:          &model.Coordinates{GroupId: "com.acme", ArtifactId: "acme-bom", Version: "1.2.0"},
+	Repositories: []model.Repository{{ID: "acme", URL: "https://repo.acme.com/maven"}},
+}
+
+func TestAddDependenciesToMaven(t *testing.T) {
+	build, err := AddDependenciesTo(MAVEN_POM, mavenPom, []model.CustomDependency{customDependency})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<artifactId>acme-client</artifactId>\n\t\t\t<scope>runtime</scope>\n\t\t</dependency>\n\t</dependencies>",
+		"<dependencyManagement>\n\t\t<dependencies>\n\t\t\t<dependency>\n\t\t\t\t<groupId>com.acme</groupId>\n\t\t\t\t<artifactId>acme-bom</artifactId>",
+		"<repositories>\n\t\t<repository>\n\t\t\t<id>acme</id>\n\t\t\t<url>https://repo.acme.com/maven</url>",
+	} {
+		if !strings.Contains(build, want) {
+			t.Errorf("the pom misses %q:\n%s", want, build)
+		}
+	}
+}
+
+func TestAddDependenciesToGradle(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{GRADLE_BUILD, []string{
+			"\truntimeOnly 'com.acme:acme-client'\n}",
+			"\tmaven { url 'https://repo.acme.com/maven' }\n}",
+			"dependencyManagement {\n\timports {\n\t\tmavenBom \"com.acme:acme-bom:1.2.0\"\n\t}\n}",
+		}},
+		{GRADLE_BUILD_KTS, []string{
+			"\truntimeOnly(\"com.acme:acme-client\")\n}",
+			"\tmaven { url = uri(\"https://repo.acme.com/maven\") }\n}",
+			"\t\tmavenBom(\"com.acme:acme-bom:1.2.0\")",
+		}},
+	}
+	for _, test := range tests {
+		build, err := AddDependenciesTo(test.name, gradleBuild, []model.CustomDependency{customDependency})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(build, want) {
+				t.Errorf("%s misses %q:\n%s", test.name, want, build)
+			}
+		}
+	}
+}
+
+func TestAddDependenciesToWithoutDependencies(t *testing.T) {
+	if _, err := AddDependenciesTo(MAVEN_POM, "<project></project>", []model.CustomDependency{customDependency}); err == nil {
+		t.Error("AddDependenciesTo() succeeded without a dependencies section, want an error")
+	}
+}
+
+func TestAddDependencies(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, MAVEN_POM)
+	if err := os.WriteFile(path, []byte(mavenPom), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddDependencies(dir, []model.CustomDependency{customDependency}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "<artifactId>acme-client</artifactId>") {
+		t.Errorf("the dependency wasn't added to the pom:\n%s", content)
+	}
+
+	if err := AddDependencies(t.TempDir(), []model.CustomDependency{customDependency}); err == nil {
+		t.Error("AddDependencies() succeeded without a build file, want an error")
+	}
+}
@@ -0,0 +1,105 @@
+package buildfile
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"tacher/src/model"
+)
+
+// the patterns match both the Groovy and the Kotlin DSL
+var (
+	gradleGroup        = regexp.MustCompile(`(?m)^\s*group\s*=\s*["']([^"']+)["']`)
+	gradleVersion      = regexp.MustCompile(`(?m)^\s*version\s*=\s*["']([^"']+)["']`)
+	gradleDescription  = regexp.MustCompile(`(?m)^\s*description\s*=\s*["']([^"']+)["']`)
+	gradleBootPlugin   = regexp.MustCompile(`id\s*\(?\s*["']org\.springframework\.boot["']\s*\)?\s*version\s*["']([^"']+)["']`)
+	gradleKotlinPlugin = regexp.MustCompile(`kotlin\s*\(\s*["']jvm["']\s*\)|id\s*\(?\s*["']org\.jetbrains\.kotlin\.jvm["']`)
+	gradleGroovyPlugin = regexp.MustCompile(`(?m)^\s*id\s*\(?\s*["']groovy["']`)
+	gradleWarPlugin    = regexp.MustCompile(`(?m)id\s*\(?\s*["']war["']|apply\s+plugin:\s*["']war["']|^\s*war\s*$`)
+	gradleToolchain    = regexp.MustCompile(`JavaLanguageVersion\.of\(\s*["']?(\d+)["']?\s*\)`)
+	gradleSourceCompat = regexp.MustCompile(`sourceCompatibility\s*=\s*(?:JavaVersion\.VERSION_([\d_]+)|["']([\d.]+)["'])`)
+	gradleDependency   = regexp.MustCompile(`(?m)^\s*(\w+)\s*\(?\s*["']([^:"']+):([^:"']+)(?::([^:"']+))?["']`)
+	gradleRootProject  = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+)
+
+// gradle configurations that declare dependencies
+var gradleConfigurations = map[string]string{
+	"implementation":          "compile",
+	"api":                     "compile",
+	"compileOnly":             "provided",
+	"runtimeOnly":             "runtime",
+	"developmentOnly":         "runtime",
+	"annotationProcessor":     "annotationProcessor",
+	"kapt":                    "annotationProcessor",
+	"testImplementation":      "test",
+	"testRuntimeOnly":         "test",
+	"providedRuntime":         "provided",
+	"testAnnotationProcessor": "test",
+}
+
+// read the settings file of a Gradle project, if any
+func readGradleSettings(dir string) ([]byte, error) {
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		if content, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return content, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// parse the content of a build.gradle(.kts) file and of its settings file
+func parseGradle(content []byte, settings []byte, kotlinDsl bool) (*Project, error) {
+	build := string(content)
+	prj := &Project{
+		Group:       firstGroup(gradleGroup, build),
+		Version:     firstGroup(gradleVersion, build),
+		Description: firstGroup(gradleDescription, build),
+		Artifact:    firstGroup(gradleRootProject, string(settings)),
+		BootVersion: firstGroup(gradleBootPlugin, build),
+		BuildTool:   BUILD_GRADLE,
+		Language:    "java",
+		Packaging:   "jar",
+	}
+	prj.Name = prj.Artifact
+	if kotlinDsl {
+		prj.BuildTool = BUILD_GRADLE_KOTLIN
+	}
+	if gradleKotlinPlugin.MatchString(build) {
+		prj.Language = "kotlin"
+	} else if gradleGroovyPlugin.MatchString(build) {
+		prj.Language = "groovy"
+	}
+	if gradleWarPlugin.MatchString(build) {
+		prj.Packaging = "war"
+	}
+
+	// the toolchain takes precedence over the source compatibility
+	if v := firstGroup(gradleToolchain, build); v != "" {
+		prj.JavaVersion = v
+	} else if m := gradleSourceCompat.FindStringSubmatch(build); m != nil {
+		prj.JavaVersion = strings.ReplaceAll(m[1]+m[2], "_", ".")
+	}
+
+	for _, m := range gradleDependency.FindAllStringSubmatch(build, -1) {
+		scope, isDependency := gradleConfigurations[m[1]]
+		if !isDependency {
+			continue
+		}
+		prj.Dependencies = append(prj.Dependencies, model.Coordinates{
+			GroupId:    m[2],
+			ArtifactId: m[3],
+			Version:    m[4],
+			Scope:      scope,
+		})
+	}
+	return prj, nil
+}
+
+// return the first group matched by the regular expression or an empty string
+func firstGroup(re *regexp.Regexp, text string) string {
+	if m := re.FindStringSubmatch(text); m != nil {
+		return m[1]
+	}
+	return ""
+}
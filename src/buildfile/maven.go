@@ -0,0 +1,121 @@
+package buildfile
+
+import (
+	"encoding/xml"
+	"strings"
+	"tacher/src/model"
+)
+
+// subset of a Maven pom.xml
+type pom struct {
+	Parent struct {
+		GroupId    string `xml:"groupId"`
+		ArtifactId string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	GroupId     string `xml:"groupId"`
+	ArtifactId  string `xml:"artifactId"`
+	Version     string `xml:"version"`
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	Packaging   string `xml:"packaging"`
+	Properties  struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []struct {
+		GroupId    string `xml:"groupId"`
+		ArtifactId string `xml:"artifactId"`
+		Version    string `xml:"version"`
+		Scope      string `xml:"scope"`
+	} `xml:"dependencies>dependency"`
+	DependencyManagement []struct {
+		GroupId    string `xml:"groupId"`
+		ArtifactId string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"dependencyManagement>dependencies>dependency"`
+	Plugins []struct {
+		GroupId    string `xml:"groupId"`
+		ArtifactId string `xml:"artifactId"`
+	} `xml:"build>plugins>plugin"`
+}
+
+// parse the content of a pom.xml file
+func parseMaven(content []byte) (*Project, error) {
+	var p pom
+	if err := xml.Unmarshal(content, &p); err != nil {
+		return nil, err
+	}
+
+	properties := make(map[string]string)
+	for _, e := range p.Properties.Entries {
+		properties[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+	resolve := func(value string) string {
+		return resolveProperties(strings.TrimSpace(value), properties)
+	}
+
+	prj := &Project{
+		Group:       resolve(p.GroupId),
+		Artifact:    resolve(p.ArtifactId),
+		Version:     resolve(p.Version),
+		Name:        resolve(p.Name),
+		Description: resolve(p.Description),
+		BuildTool:   BUILD_MAVEN,
+		Language:    "java",
+		Packaging:   resolve(p.Packaging),
+		JavaVersion: properties["java.version"],
+	}
+	if prj.Group == "" {
+		prj.Group = resolve(p.Parent.GroupId)
+	}
+	if prj.Version == "" {
+		prj.Version = resolve(p.Parent.Version)
+	}
+	if prj.JavaVersion == "" {
+		prj.JavaVersion = properties["maven.compiler.release"]
+	}
+	if prj.JavaVersion == "" {
+		prj.JavaVersion = properties["maven.compiler.source"]
+	}
+
+	// the Spring Boot version comes either from the parent or from the imported BOM
+	if p.Parent.GroupId == "org.springframework.boot" && p.Parent.ArtifactId == "spring-boot-starter-parent" {
+		prj.BootVersion = resolve(p.Parent.Version)
+	}
+	for _, d := range p.DependencyManagement {
+		if prj.BootVersion == "" && d.GroupId == "org.springframework.boot" && d.ArtifactId == "spring-boot-dependencies" {
+			prj.BootVersion = resolve(d.Version)
+		}
+	}
+
+	// the language is defined by the compiler plugin
+	for _, plugin := range p.Plugins {
+		switch plugin.ArtifactId {
+		case "kotlin-maven-plugin":
+			prj.Language = "kotlin"
+		case "gmavenplus-plugin":
+			prj.Language = "groovy"
+		}
+	}
+
+	for _, d := range p.Dependencies {
+		prj.Dependencies = append(prj.Dependencies, model.Coordinates{
+			GroupId:    resolve(d.GroupId),
+			ArtifactId: resolve(d.ArtifactId),
+			Version:    resolve(d.Version),
+			Scope:      resolve(d.Scope),
+		})
+	}
+	return prj, nil
+}
+
+// replace '${property}' placeholders with the property's value
+func resolveProperties(value string, properties map[string]string) string {
+	for k, v := range properties {
+		value = strings.ReplaceAll(value, "${"+k+"}", v)
+	}
+	return value
+}
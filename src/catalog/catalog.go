@@ -0,0 +1,155 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"tacher/src/errs"
+	"tacher/src/model"
+	"tacher/src/utils"
+	"tacher/src/version"
+
+	"gopkg.in/yaml.v3"
+)
+
+const DEFAULT_CATALOG = "catalog.yaml"
+
+// local catalog of dependencies that Spring initializer doesn't know
+type catalog struct {
+	Categories []struct {
+		Name         string       `yaml:"name"`
+		Dependencies []dependency `yaml:"dependencies"`
+	} `yaml:"categories"`
+}
+
+// dependency of the catalog
+type dependency struct {
+	ID           string       `yaml:"id"`
+	Name         string       `yaml:"name"`
+	Description  string       `yaml:"description"`
+	GroupId      string       `yaml:"groupId"`
+	ArtifactId   string       `yaml:"artifactId"`
+	Version      string       `yaml:"version"`
+	Scope        string       `yaml:"scope"`
+	VersionRange string       `yaml:"versionRange"`
+	Bom          *coordinates `yaml:"bom"`
+}
+
+// Maven coordinates of a BOM
+type coordinates struct {
+	GroupId    string `yaml:"groupId"`
+	ArtifactId string `yaml:"artifactId"`
+	Version    string `yaml:"version"`
+}
+
+// merge the dependencies of the given catalog files in the app's state. The default catalog
+// in tacher's configuration directory is merged too, if it exists
+func Merge(files []string, state *model.AppState) error {
+	if dir, err := utils.ConfigDir(); err == nil {
+		if def := filepath.Join(dir, DEFAULT_CATALOG); !utils.Contains(files, def) {
+			if _, err := os.Stat(def); err == nil {
+				files = append([]string{def}, files...)
+			}
+		}
+	}
+
+	for _, file := range files {
+		if err := merge(file, state); err != nil {
+			return fmt.Errorf("can't load catalog %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// merge the dependencies of a catalog file in the app's state
+func merge(file string, state *model.AppState) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var c catalog
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return err
+	}
+
+	known := knownIDs(state)
+	for _, category := range c.Categories {
+		if category.Name == "" {
+			return errs.Input("a category has no name")
+		}
+		for _, d := range category.Dependencies {
+			dep, err := d.toValue()
+			if err != nil {
+				return err
+			}
+			if known[dep.ID] {
+				return errs.Input("dependency %s is already defined", dep.ID)
+			}
+			known[dep.ID] = true
+			state.Dependency[category.Name] = append(state.Dependency[category.Name], dep)
+		}
+		sort.Sort(model.ValueWithDescByName(state.Dependency[category.Name]))
+	}
+	return nil
+}
+
+// merge the dependencies of another Spring initializer instance in the app's state, in the
+// categories of the same name. The dependencies that the app already knows are left out, so the
+// instance in use wins
+func MergeServer(name string, deps map[string][]model.ValueWithDesc, state *model.AppState) {
+	known := knownIDs(state)
+	for category, values := range deps {
+		for _, d := range values {
+			if known[d.ID] {
+				continue
+			}
+			known[d.ID] = true
+			d.Source = name
+			state.Dependency[category] = append(state.Dependency[category], d)
+		}
+		sort.Sort(model.ValueWithDescByName(state.Dependency[category]))
+	}
+}
+
+// IDs of the dependencies of all the categories
+func knownIDs(state *model.AppState) map[string]bool {
+	known := make(map[string]bool)
+	for _, deps := range state.Dependency {
+		for _, d := range deps {
+			known[d.ID] = true
+		}
+	}
+	return known
+}
+
+// validate the dependency and map it on the app's model
+func (d dependency) toValue() (model.ValueWithDesc, error) {
+	if d.ID == "" || d.GroupId == "" || d.ArtifactId == "" {
+		return model.ValueWithDesc{}, errs.Input("dependency %q must have an id, a groupId and an artifactId", d.ID)
+	}
+	if _, err := version.ParseRange(d.VersionRange); err != nil {
+		return model.ValueWithDesc{}, errs.Input("dependency %s: %w", d.ID, err)
+	}
+
+	custom := &model.CustomDependency{
+		Coordinates: model.Coordinates{
+			GroupId:    d.GroupId,
+			ArtifactId: d.ArtifactId,
+			Version:    d.Version,
+			Scope:      utils.NonNullOrElse(d.Scope, "compile"),
+		},
+		VersionRange: d.VersionRange,
+	}
+	if d.Bom != nil {
+		custom.Bom = &model.Coordinates{GroupId: d.Bom.GroupId, ArtifactId: d.Bom.ArtifactId, Version: d.Bom.Version, Scope: "import"}
+	}
+	return model.ValueWithDesc{
+		ID:           d.ID,
+		Name:         utils.NonNullOrElse(d.Name, d.ID),
+		Description:  d.Description,
+		VersionRange: d.VersionRange,
+		Coordinates:  &custom.Coordinates,
+		Custom:       custom,
+	}, nil
+}
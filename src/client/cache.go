@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"tacher/src/logging"
+	"time"
+)
+
+// endpoints whose responses are cached
+const METADATA_ENDPOINT = "metadata/client"
+const DEPENDENCIES_ENDPOINT = "dependencies"
+
+// directory of the cached responses, caching is disabled if it's empty
+var cacheDir string
+
+// age after which the cached responses are fetched again
+var cacheTTL time.Duration
+
+// guards the cache's settings and files, it isn't held during the requests
+var cacheMutex sync.Mutex
+
+// endpoints of the servers whose cached response was replaced by a different one during this run
+var replaced = make(map[string]bool)
+
+// requests in progress by server, language and endpoint, shared by the concurrent callers
+var inflight = make(map[string]*request)
+
+// request whose response is shared once done is closed
+type request struct {
+	done     chan struct{}
+	response []byte
+	err      error
+}
+
+// cache the responses of Spring initializer's metadata in the directory for the given time,
+// an empty directory or a zero time disables the cache
+func SetCache(dir string, ttl time.Duration) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	cacheDir = dir
+	cacheTTL = ttl
+}
+
+// file caching the endpoint of the server in the current language
+func cacheFile(server string, endpoint string) string {
+	hash := sha1.Sum([]byte(server + language + endpoint))
+	return filepath.Join(cacheDir, hex.EncodeToString(hash[:])+".json")
+}
+
+// file keeping the cached response of the endpoint that a different one replaced
+func previousFile(server string, endpoint string) string {
+	return strings.TrimSuffix(cacheFile(server, endpoint), ".json") + ".previous.json"
+}
+
+// get the server's endpoint response from the cache while it's fresh, from the server otherwise. When
+// the server can't be reached the stale response is used. With onlyCache the server is never
+// contacted and the response is used whatever its age
+func cached(server string, endpoint string, onlyCache bool) ([]byte, error) {
+	cacheMutex.Lock()
+	if cacheDir == "" || (cacheTTL <= 0 && !onlyCache) {
+		cacheMutex.Unlock()
+		if onlyCache {
+			return nil, errors.New("the cache is disabled")
+		}
+		return fetchShared(server, endpoint, false)
+	}
+	file := cacheFile(server, endpoint)
+	info, statErr := os.Stat(file)
+	if statErr == nil && (onlyCache || time.Since(info.ModTime()) < cacheTTL) {
+		defer cacheMutex.Unlock()
+		logging.Debug("cached response used", "endpoint", endpoint, "file", file, "age", time.Since(info.ModTime()))
+		return os.ReadFile(file)
+	}
+	cacheMutex.Unlock()
+	if onlyCache {
+		return nil, fmt.Errorf("%s was never cached: %w", endpoint, statErr)
+	}
+
+	response, err := fetchShared(server, endpoint, true)
+	if err != nil {
+		if statErr == nil {
+			cacheMutex.Lock()
+			defer cacheMutex.Unlock()
+			logging.Warn("stale cached response used", "endpoint", endpoint, "file", file, "age", time.Since(info.ModTime()), "error", err)
+			return os.ReadFile(file)
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// get the endpoint's response from the server and cache it, whatever the age of the cached one
+func refreshed(endpoint string) ([]byte, error) {
+	return fetchShared(serverURL, endpoint, true)
+}
+
+// get the endpoint's response from the server and cache it if asked. Concurrent callers asking for
+// the same endpoint share a single request, the others don't wait for each other
+func fetchShared(server string, endpoint string, cache bool) ([]byte, error) {
+	cacheMutex.Lock()
+	key := server + "\n" + language + "\n" + endpoint
+	r, found := inflight[key]
+	if !found {
+		r = &request{done: make(chan struct{})}
+		inflight[key] = r
+	}
+	cacheMutex.Unlock()
+	if found {
+		<-r.done
+		return r.response, r.err
+	}
+
+	r.response, r.err = fetch(server, endpoint)
+	cacheMutex.Lock()
+	delete(inflight, key)
+	if r.err == nil && cache && cacheDir != "" {
+		store(server, endpoint, r.response)
+	}
+	cacheMutex.Unlock()
+	close(r.done)
+	return r.response, r.err
+}
+
+// write the server's endpoint response in the cache, the cache's mutex must be held. A different
+// cached response is kept as the previous one, with its time
+func store(server string, endpoint string, response []byte) {
+	file := cacheFile(server, endpoint)
+	// a cache that can't be written only means more requests
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return
+	}
+	if old, err := os.ReadFile(file); err == nil && !bytes.Equal(old, response) {
+		if err := os.Rename(file, previousFile(server, endpoint)); err == nil {
+			logging.Info("cached response replaced", "endpoint", endpoint, "previous", previousFile(server, endpoint))
+			replaced[server+endpoint] = true
+		}
+	}
+	os.WriteFile(file, response, 0644)
+}
+
+// the response that a different one replaced in the cache and the time when it was fetched
+func previous(endpoint string) ([]byte, time.Time, error) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	if cacheDir == "" {
+		return nil, time.Time{}, errors.New("the cache is disabled")
+	}
+	file := previousFile(serverURL, endpoint)
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	response, err := os.ReadFile(file)
+	return response, info.ModTime(), err
+}
+
+// time when the endpoint's response was cached, zero if it wasn't
+func CachedAt(endpoint string) time.Time {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	if cacheDir == "" {
+		return time.Time{}
+	}
+	if info, err := os.Stat(cacheFile(serverURL, endpoint)); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
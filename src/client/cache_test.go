@@ -0,0 +1,83 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// server answering its path, the requests to /slow wait until release is closed
+func slowServer(t *testing.T, release chan struct{}) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	t.Cleanup(server.Close)
+	SetCache(t.TempDir(), time.Hour)
+	t.Cleanup(func() { SetCache("", 0) })
+	return server, &hits
+}
+
+func TestCachedSharesRequests(t *testing.T) {
+	release := make(chan struct{})
+	server, hits := slowServer(t, release)
+
+	var wg sync.WaitGroup
+	responses := make([]string, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := cached(server.URL+"/", "slow", false)
+			if err != nil {
+				t.Error(err)
+			}
+			responses[i] = string(response)
+		}(i)
+	}
+	// let the callers line up behind the first request
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, r := range responses {
+		if r != "/slow" {
+			t.Errorf("response = %q, want /slow", r)
+		}
+	}
+	if _, err := cached(server.URL+"/", "slow", false); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(hits); n != 1 {
+		t.Errorf("%d requests sent, want 1", n)
+	}
+}
+
+func TestCachedDoesntWaitForOtherEndpoints(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server, _ := slowServer(t, release)
+
+	go cached(server.URL+"/", "slow", false)
+	time.Sleep(50 * time.Millisecond)
+	done := make(chan string)
+	go func() {
+		response, _ := cached(server.URL+"/", "fast", false)
+		done <- string(response)
+	}()
+	select {
+	case r := <-done:
+		if r != "/fast" {
+			t.Errorf("response = %q, want /fast", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("the request waited for the one of another endpoint")
+	}
+}
@@ -3,16 +3,22 @@ package client
 import (
 	"archive/zip"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"tacher/src/errs"
+	"tacher/src/logging"
 	"tacher/src/model"
 	"tacher/src/utils"
+	"time"
 
 	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/oj"
@@ -20,13 +26,90 @@ import (
 
 const SPRING_URL = "https://start.spring.io/"
 
+// client used for all the requests to Spring initializer
+var httpClient = &http.Client{}
+
+// language asked to Spring initializer, empty for the server's default
+var language string
+
+// time when the metadata in use was fetched from Spring initializer
+var metadataTime time.Time
+
+// URL of the Spring initializer instance, always ending with a slash
+var serverURL = SPRING_URL
+
+// use the Spring initializer instance at the given URL, the default one if the URL is empty
+func SetServerURL(url string) {
+	serverURL = NormalizeURL(utils.NonNullOrElse(url, SPRING_URL))
+}
+
+// URL of a Spring initializer instance ending with a slash, like the one in use
+func NormalizeURL(url string) string {
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	return url
+}
+
+// ask Spring initializer for the descriptions in the language, English is the fallback
+func SetLanguage(lang string) {
+	language = lang
+}
+
+// URL of the Spring initializer instance in use
+func ServerURL() string {
+	return serverURL
+}
+
+// limit the requests sent to each host to the given number per second, zero removes the limit
+func SetRateLimit(perSecond float64) {
+	if perSecond <= 0 {
+		httpClient.Transport = nil
+		return
+	}
+	httpClient.Transport = newRateLimitedTransport(http.DefaultTransport, perSecond)
+}
+
+// give up the requests taking longer than the timeout, zero means no timeout
+func SetTimeout(timeout time.Duration) {
+	httpClient.Timeout = timeout
+}
+
 // generates the project package from the given data
 func Generate(data *model.AppData) error {
-	req, err := http.NewRequest("GET", SPRING_URL+"starter.zip", nil)
+	archive, err := getProjectFile("starter.zip", data)
 	if err != nil {
 		return err
 	}
 
+	if err := unzip(archive, data.Path); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// gets the build file that Spring initializer generates for the given data, the pom.xml for Maven
+// projects and the build.gradle for Gradle ones
+func GetBuildFile(data *model.AppData) (string, error) {
+	file := "build.gradle"
+	if strings.HasPrefix(data.SpringBuildTool, "maven") {
+		file = "pom.xml"
+	}
+	content, err := getProjectFile(file, data)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// gets one of the files that Spring initializer generates from the project's data
+func getProjectFile(file string, data *model.AppData) ([]byte, error) {
+	req, err := http.NewRequest("GET", serverURL+file, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	q := req.URL.Query()
 	q.Add("type", data.SpringBuildTool)
 	q.Add("language", data.Language)
@@ -41,9 +124,9 @@ func Generate(data *model.AppData) error {
 	q.Add("javaVersion", data.JavaVersion)
 	q.Add("dependencies", strings.Join(utils.Map(data.Dependencies, func(v model.ValueWithDesc) string { return v.ID }), ","))
 	req.URL.RawQuery = q.Encode()
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := send(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer utils.CheckClose(resp.Body)
 
@@ -52,42 +135,102 @@ func Generate(data *model.AppData) error {
 		if resp.Body != nil {
 			body, err := ioutil.ReadAll(resp.Body)
 			if err != nil {
-				return fmt.Errorf("unexpected response code [%d]. Can't read error message [%w]", resp.StatusCode, err)
+				return nil, fmt.Errorf("%w Can't read error message [%s]", errs.NewServerError(resp.StatusCode, "", ""), err)
 			}
 			errorMessage, err := getErrorMessageFromResponse(body)
 			if err != nil {
-				return fmt.Errorf("unexpected response code [%d]. Can't parse error message [%w]", resp.StatusCode, err)
+				return nil, fmt.Errorf("%w Can't parse error message [%s]", errs.NewServerError(resp.StatusCode, "", ""), err)
 			}
-			return fmt.Errorf("unexpected response code [%d]. Message: [%s]", resp.StatusCode, errorMessage)
+			logging.Warn("request rejected", "url", req.URL, "status", resp.StatusCode, "message", errorMessage)
+			return nil, errs.NewServerError(resp.StatusCode, errorMessage, "")
 		}
 		// no body in the message. return generic error
-		return fmt.Errorf("unexpected response code [%d].", resp.StatusCode)
+		return nil, errs.NewServerError(resp.StatusCode, "", "")
 	}
 
 	// read the response
-	archive, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+	return readBody(resp)
+}
+
+// gets the options from Spring initializer and puts them in the app's state
+func GetOptions(state *model.AppState) error {
+	// get data from Spring's website, or from the cache while it's fresh
+	response, err := cached(serverURL, METADATA_ENDPOINT, false)
+	if unavailable(err) {
+		// the embedded snapshot is the last resort when its server can't be reached
+		return useEmbeddedSnapshot(state, err)
+	} else if err != nil {
 		return err
 	}
+	if err := parseOptions(response, state); err != nil {
+		return &errs.MetadataError{Err: err}
+	}
+	setMetadataTime()
+	return nil
+}
 
-	if err := unzip(archive, data.Path); err != nil {
+// gets the options from Spring initializer, whatever the age of the cached ones, and caches them
+func RefreshOptions(state *model.AppState) error {
+	response, err := refreshed(METADATA_ENDPOINT)
+	if err != nil {
 		return err
 	}
-
+	if err := parseOptions(response, state); err != nil {
+		return &errs.MetadataError{Err: err}
+	}
+	metadataTime = time.Now()
 	return nil
 }
 
-// gets the options from Spring initializer and puts them in the app's state
-func GetOptions(state *model.AppState) error {
-	// get data from Spring's website
-	resp, err := http.Get(SPRING_URL + "metadata/client")
+// gets the options that different ones replaced in the cache, during this run or a previous one.
+// Returns the time when they were fetched
+func GetPreviousOptions(state *model.AppState) (time.Time, error) {
+	response, fetched, err := previous(METADATA_ENDPOINT)
 	if err != nil {
-		return err
+		return time.Time{}, err
+	}
+	if err := parseOptions(response, state); err != nil {
+		return time.Time{}, &errs.MetadataError{Err: err}
+	}
+	return fetched, nil
+}
+
+// check if different options replaced the cached ones during this run
+func OptionsReplaced() bool {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	return replaced[serverURL+METADATA_ENDPOINT]
+}
+
+// the metadata was just fetched unless it comes from the cache
+func setMetadataTime() {
+	metadataTime = CachedAt(METADATA_ENDPOINT)
+	if metadataTime.IsZero() {
+		metadataTime = time.Now()
 	}
-	response, err := ioutil.ReadAll(resp.Body)
+}
+
+// time when the metadata of the last options was fetched from Spring initializer, zero if no
+// options were got
+func MetadataTime() time.Time {
+	return metadataTime
+}
+
+// gets the options from the cache only, whatever their age. Fails if they were never cached
+func GetCachedOptions(state *model.AppState) error {
+	response, err := cached(serverURL, METADATA_ENDPOINT, true)
 	if err != nil {
 		return err
 	}
+	if err := parseOptions(response, state); err != nil {
+		return &errs.MetadataError{Err: err}
+	}
+	setMetadataTime()
+	return nil
+}
+
+// parse Spring initializer's metadata and put the options in the app's state
+func parseOptions(response []byte, state *model.AppState) error {
 	obj, err := oj.Parse(response)
 	if err != nil {
 		return err
@@ -103,7 +246,7 @@ func GetOptions(state *model.AppState) error {
 		"$.packageName.default": &state.DefaultPackageName,
 	}
 	for k, v := range textDefaults {
-		if def, err := extract[string](obj, k, func(i []interface{}) interface{} { return i[0] }); err == nil {
+		if def, err := extract[string](obj, k, first); err == nil {
 			*v = def
 		}
 	}
@@ -113,7 +256,7 @@ func GetOptions(state *model.AppState) error {
 	if err != nil {
 		return err
 	}
-	if def, err := extract[string](obj, "$.type.default", func(i []interface{}) interface{} { return i[0] }); err == nil {
+	if def, err := extract[string](obj, "$.type.default", first); err == nil {
 		if idx, found := utils.Find(state.SpringBuildTools, func(st model.ValueWithDesc) bool { return st.ID == def }); found {
 			state.DefaultSpringBuildTool = idx
 		} else {
@@ -128,7 +271,7 @@ func GetOptions(state *model.AppState) error {
 	if err != nil {
 		return err
 	}
-	if def, err := extract[string](obj, "$.packaging.default", func(i []interface{}) interface{} { return i[0] }); err == nil {
+	if def, err := extract[string](obj, "$.packaging.default", first); err == nil {
 		if idx, found := utils.Find(state.Packaging, func(p model.Value) bool { return p.ID == def }); found {
 			state.DefaultPackaging = idx
 		} else {
@@ -143,7 +286,7 @@ func GetOptions(state *model.AppState) error {
 	if err != nil {
 		return err
 	}
-	if def, err := extract[string](obj, "$.javaVersion.default", func(i []interface{}) interface{} { return i[0] }); err == nil {
+	if def, err := extract[string](obj, "$.javaVersion.default", first); err == nil {
 		if idx, found := utils.Find(state.JavaVersions, func(v model.Value) bool { return v.ID == def }); found {
 			state.DefaultJavaVersion = idx
 		} else {
@@ -158,7 +301,7 @@ func GetOptions(state *model.AppState) error {
 	if err != nil {
 		return err
 	}
-	if def, err := extract[string](obj, "$.language.default", func(i []interface{}) interface{} { return i[0] }); err == nil {
+	if def, err := extract[string](obj, "$.language.default", first); err == nil {
 		if idx, found := utils.Find(state.Languages, func(l model.Value) bool { return l.ID == def }); found {
 			state.DefaultLanguage = idx
 		} else {
@@ -173,7 +316,7 @@ func GetOptions(state *model.AppState) error {
 	if err != nil {
 		return err
 	}
-	if def, err := extract[string](obj, "$.bootVersion.default", func(i []interface{}) interface{} { return i[0] }); err == nil {
+	if def, err := extract[string](obj, "$.bootVersion.default", first); err == nil {
 		if idx, found := utils.Find(state.SpringVersions, func(v model.Value) bool { return v.ID == def }); found {
 			state.DefaultSpringVersion = idx
 		} else {
@@ -192,28 +335,199 @@ func GetOptions(state *model.AppState) error {
 	return nil
 }
 
+// gets the Maven coordinates of the dependencies available for the given Spring Boot version,
+// the default one if it's empty. Returns a map where each key is the dependency ID. The embedded
+// snapshot gives the coordinates of its default version once its metadata is in use
+func GetDependencyCoordinates(bootVersion string) (map[string]model.Coordinates, error) {
+	if snapshotInUse != nil {
+		if len(snapshotInUse.Dependencies) == 0 {
+			return nil, &errs.MetadataError{Err: errors.New("the snapshot has no coordinates")}
+		}
+		return parseCoordinates(snapshotInUse.Dependencies)
+	}
+	endpoint := DEPENDENCIES_ENDPOINT
+	if bootVersion != "" {
+		endpoint += "?" + url.Values{"bootVersion": {bootVersion}}.Encode()
+	}
+	response, err := cached(serverURL, endpoint, false)
+	if err != nil {
+		return nil, fmt.Errorf("can't get the dependencies' coordinates: %w", err)
+	}
+	return parseCoordinates(response)
+}
+
+// gets the dependencies of another Spring initializer instance by category, as custom dependencies
+// added to the build file generated by the instance in use. Their coordinates are the ones of the
+// other instance's default Spring Boot version, the dependencies without coordinates are left out
+func GetServerDependencies(server string) (map[string][]model.ValueWithDesc, error) {
+	server = NormalizeURL(server)
+	response, err := cached(server, METADATA_ENDPOINT, false)
+	if err != nil {
+		return nil, err
+	}
+	state := new(model.AppState)
+	if err := parseOptions(response, state); err != nil {
+		return nil, &errs.MetadataError{Err: err}
+	}
+	response, err = cached(server, DEPENDENCIES_ENDPOINT, false)
+	if err != nil {
+		return nil, fmt.Errorf("can't get the dependencies' coordinates: %w", err)
+	}
+	custom, err := parseCustomDependencies(response)
+	if err != nil {
+		return nil, &errs.MetadataError{Err: err}
+	}
+
+	ret := make(map[string][]model.ValueWithDesc)
+	for category, deps := range state.Dependency {
+		for _, d := range deps {
+			c, found := custom[d.ID]
+			if !found {
+				logging.Debug("dependency without coordinates left out", "server", logging.Redact(server), "dependency", d.ID)
+				continue
+			}
+			c.VersionRange = d.VersionRange
+			d.Custom = &c
+			d.Coordinates = &c.Coordinates
+			ret[category] = append(ret[category], d)
+		}
+	}
+	return ret, nil
+}
+
+// response of the dependencies endpoint, the dependencies refer to their BOM and repository by ID
+type dependenciesResponse struct {
+	Dependencies map[string]struct {
+		model.Coordinates
+		Bom        string
+		Repository string
+	}
+	Boms map[string]struct {
+		model.Coordinates
+		Repositories []string
+	}
+	Repositories map[string]struct {
+		Name string
+		URL  string
+	}
+}
+
+// parse the coordinates of the dependencies with their BOM and repositories, returns a map where
+// each key is the dependency ID
+func parseCustomDependencies(response []byte) (map[string]model.CustomDependency, error) {
+	var r dependenciesResponse
+	if err := json.Unmarshal(response, &r); err != nil {
+		return nil, err
+	}
+	repository := func(id string) (model.Repository, bool) {
+		repo, found := r.Repositories[id]
+		return model.Repository{ID: id, Name: repo.Name, URL: repo.URL}, found && repo.URL != ""
+	}
+
+	ret := make(map[string]model.CustomDependency, len(r.Dependencies))
+	for id, d := range r.Dependencies {
+		c := model.CustomDependency{Coordinates: d.Coordinates}
+		c.Coordinates.Scope = utils.NonNullOrElse(c.Coordinates.Scope, "compile")
+		if repo, found := repository(d.Repository); found {
+			c.Repositories = append(c.Repositories, repo)
+		}
+		if bom, found := r.Boms[d.Bom]; found {
+			c.Bom = &model.Coordinates{GroupId: bom.GroupId, ArtifactId: bom.ArtifactId, Version: bom.Version, Scope: "import"}
+			for _, id := range bom.Repositories {
+				if repo, found := repository(id); found {
+					c.Repositories = append(c.Repositories, repo)
+				}
+			}
+		}
+		ret[id] = c
+	}
+	return ret, nil
+}
+
+// parse the coordinates of the dependencies, returns a map where each key is the dependency ID
+func parseCoordinates(response []byte) (map[string]model.Coordinates, error) {
+	obj, err := oj.Parse(response)
+	if err != nil {
+		return nil, err
+	}
+	coordinates, err := extract[map[string]model.Coordinates](obj, "$.dependencies", first)
+	if err != nil {
+		return nil, err
+	}
+	if coordinates == nil {
+		return nil, &errs.MetadataError{Err: errors.New("no dependencies in the response")}
+	}
+	return coordinates, nil
+}
+
+// get one of the endpoints of a Spring initializer instance
+func fetch(server string, endpoint string) ([]byte, error) {
+	req, err := http.NewRequest("GET", server+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.CheckClose(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return nil, errs.NewServerError(resp.StatusCode, "", endpoint)
+	}
+	return readBody(resp)
+}
+
+// send the request to Spring initializer, logging its outcome
+func send(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	if language != "" {
+		req.Header.Set("Accept-Language", language+", en;q=0.5")
+	}
+	logging.Debug("request sent", "method", req.Method, "url", req.URL, "language", language)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logging.Error("request failed", "method", req.Method, "url", req.URL, "duration", time.Since(start), "error", err)
+		return nil, &errs.NetworkError{Err: err}
+	}
+	logging.Debug("response received", "method", req.Method, "url", req.URL, "status", resp.StatusCode, "duration", time.Since(start))
+	return resp, nil
+}
+
+// read the response's body, the connection can fail while reading it
+func readBody(resp *http.Response) ([]byte, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &errs.NetworkError{Err: err}
+	}
+	return body, nil
+}
+
 // unzip the archive in the given directory
 func unzip(archive []byte, dest string) error {
 	arch, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
 	if err != nil {
 		return err
 	}
+	logging.Debug("unzipping the project", "dir", dest, "entries", len(arch.File), "size", len(archive))
 
 	for _, f := range arch.File {
 		filePath := filepath.Join(dest, f.Name)
+		logging.Debug("archive entry", "name", f.Name, "size", f.UncompressedSize64)
 
 		if f.FileInfo().IsDir() {
 			os.MkdirAll(filePath, os.ModePerm)
 			continue
 		}
 
+		// a file where a directory is expected, or the other way around, means the path is taken
 		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-			return fmt.Errorf("can't create directory for %s. %w", filePath, err)
+			return &errs.ConflictError{Path: filePath, Err: err}
 		}
 
 		dst, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
 		if err != nil {
-			return err
+			return &errs.ConflictError{Path: filePath, Err: err}
 		}
 
 		archiveFile, err := f.Open()
@@ -244,15 +558,70 @@ func extractDependencies(obj interface{}) (map[string][]model.ValueWithDesc, err
 	for _, value := range values {
 		name := value.(map[string]interface{})["name"].(string)
 		ret[name], err = extract[[]model.ValueWithDesc](value, "$.values[*]", nil)
-		sort.Sort(model.ValueWithDescByName(ret[name]))
 		if err != nil {
 			return nil, err
 		}
+		// version ranges and links are read from the raw dependencies, which have the same order
+		raw, _ := value.(map[string]interface{})["values"].([]interface{})
+		for i := range ret[name] {
+			if i < len(raw) {
+				if dep, isMap := raw[i].(map[string]interface{}); isMap {
+					ret[name][i].VersionRange, _ = dep["versionRange"].(string)
+					ret[name][i].Links = extractLinks(dep)
+				}
+			}
+		}
+		sort.Sort(model.ValueWithDescByName(ret[name]))
 	}
 
 	return ret, nil
 }
 
+// extract the links of a dependency, the reference documentation comes first. Each relation
+// can have a single link or a list of links
+func extractLinks(dep map[string]interface{}) []model.Link {
+	links, _ := dep["_links"].(map[string]interface{})
+	rels := make([]string, 0, len(links))
+	for rel := range links {
+		rels = append(rels, rel)
+	}
+	sort.Slice(rels, func(i, j int) bool {
+		if (rels[i] == "reference") != (rels[j] == "reference") {
+			return rels[i] == "reference"
+		}
+		return rels[i] < rels[j]
+	})
+
+	ret := make([]model.Link, 0)
+	for _, rel := range rels {
+		values, isList := links[rel].([]interface{})
+		if !isList {
+			values = []interface{}{links[rel]}
+		}
+		for _, v := range values {
+			link, isMap := v.(map[string]interface{})
+			if !isMap {
+				continue
+			}
+			href, _ := link["href"].(string)
+			title, _ := link["title"].(string)
+			templated, _ := link["templated"].(bool)
+			if href != "" {
+				ret = append(ret, model.Link{Rel: rel, Href: href, Title: title, Templated: templated})
+			}
+		}
+	}
+	return ret
+}
+
+// merge function keeping the first result of the json path, nil if there's none
+func first(results []interface{}) interface{} {
+	if len(results) == 0 {
+		return nil
+	}
+	return results[0]
+}
+
 // map the result of the json path on the input interface and maps it on the given type
 func extract[T interface{}](obj interface{}, jsonPath string, mergeFunction func([]interface{}) interface{}) (T, error) {
 	if mergeFunction == nil {
@@ -282,5 +651,10 @@ func getErrorMessageFromResponse(response []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return path.Get(parsed)[0].(string), nil
+	if found := path.Get(parsed); len(found) > 0 {
+		if message, isString := found[0].(string); isString {
+			return message, nil
+		}
+	}
+	return "", fmt.Errorf("no message in the response")
 }
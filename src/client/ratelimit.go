@@ -0,0 +1,43 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// transport that spaces out the requests sent to the same host
+type rateLimitedTransport struct {
+	next     http.RoundTripper
+	interval time.Duration
+	mutex    sync.Mutex
+	// time at which the next request to each host can be sent
+	slots map[string]time.Time
+}
+
+func newRateLimitedTransport(next http.RoundTripper, perSecond float64) *rateLimitedTransport {
+	return &rateLimitedTransport{
+		next:     next,
+		interval: time.Duration(float64(time.Second) / perSecond),
+		slots:    make(map[string]time.Time),
+	}
+}
+
+// wait for the host's next slot, then send the request
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mutex.Lock()
+	now := time.Now()
+	slot := t.slots[req.URL.Host]
+	if slot.Before(now) {
+		slot = now
+	}
+	t.slots[req.URL.Host] = slot.Add(t.interval)
+	t.mutex.Unlock()
+
+	select {
+	case <-time.After(time.Until(slot)):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"tacher/src/errs"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"time"
+)
+
+// metadata of start.spring.io embedded in tacher, used when neither the server nor the cache can
+// give it. Refreshed with tacher metadata export
+//
+//go:embed snapshot.json
+var embeddedSnapshot []byte
+
+// metadata of a Spring initializer instance saved in a file, to be carried onto the machines
+// without network
+type Snapshot struct {
+	// Spring initializer instance the metadata comes from
+	Server string `json:"server"`
+	// time when the metadata was fetched from the server
+	Time time.Time `json:"time"`
+	// responses of the metadata and dependencies endpoints, the dependencies' one is optional
+	Metadata     json.RawMessage `json:"metadata"`
+	Dependencies json.RawMessage `json:"dependencies,omitempty"`
+}
+
+// snapshot whose metadata is in use, nil if it comes from the server or from the cache
+var snapshotInUse *Snapshot
+
+// take a snapshot of the current server's metadata, from the cache while it's fresh
+func TakeSnapshot() (*Snapshot, error) {
+	metadata, err := cached(serverURL, METADATA_ENDPOINT, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseOptions(metadata, new(model.AppState)); err != nil {
+		return nil, &errs.MetadataError{Err: err}
+	}
+	s := &Snapshot{Server: serverURL, Time: CachedAt(METADATA_ENDPOINT), Metadata: metadata}
+	if s.Time.IsZero() {
+		s.Time = time.Now()
+	}
+	// the snapshot can do without the coordinates, but then --like only maps the starters by their
+	// name and the dependencies' details have no coordinates
+	if dependencies, err := cached(serverURL, DEPENDENCIES_ENDPOINT, false); err == nil {
+		s.Dependencies = dependencies
+	} else {
+		logging.Warn("the snapshot has no coordinates of the dependencies", "error", err)
+	}
+	return s, nil
+}
+
+// read a snapshot written by Write, its metadata must be valid
+func ReadSnapshot(file string) (*Snapshot, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errs.Input("can't read the snapshot: %w", err)
+	}
+	return parseSnapshot(content)
+}
+
+func parseSnapshot(content []byte) (*Snapshot, error) {
+	s := new(Snapshot)
+	if err := json.Unmarshal(content, s); err != nil {
+		return nil, &errs.MetadataError{Err: fmt.Errorf("invalid snapshot: %w", err)}
+	}
+	if len(s.Metadata) == 0 {
+		return nil, &errs.MetadataError{Err: errors.New("the snapshot has no metadata")}
+	}
+	if err := parseOptions(s.Metadata, new(model.AppState)); err != nil {
+		return nil, &errs.MetadataError{Err: err}
+	}
+	return s, nil
+}
+
+// write the snapshot in the file as JSON
+func (s *Snapshot) Write(file string) error {
+	content, err := json.MarshalIndent(s, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(content, '\n'), 0644)
+}
+
+// put the snapshot's metadata in the cache of the current server, as if it had been fetched at
+// the snapshot's time, now if it's unknown
+func (s *Snapshot) Import() error {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	if cacheDir == "" {
+		return errs.Input("the cache is disabled, set cache.dir to import the metadata")
+	}
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return fmt.Errorf("can't create the cache: %w", err)
+	}
+	fetched := s.Time
+	if fetched.IsZero() {
+		fetched = time.Now()
+	}
+	responses := map[string][]byte{METADATA_ENDPOINT: s.Metadata}
+	if len(s.Dependencies) > 0 {
+		responses[DEPENDENCIES_ENDPOINT] = s.Dependencies
+	}
+	for endpoint, response := range responses {
+		file := cacheFile(serverURL, endpoint)
+		if err := os.WriteFile(file, response, 0644); err != nil {
+			return fmt.Errorf("can't write the cache: %w", err)
+		}
+		if err := os.Chtimes(file, fetched, fetched); err != nil {
+			return fmt.Errorf("can't write the cache: %w", err)
+		}
+		logging.Debug("snapshot imported", "endpoint", endpoint, "file", file, "time", fetched)
+	}
+	return nil
+}
+
+// snapshot embedded in tacher
+func EmbeddedSnapshot() (*Snapshot, error) {
+	return parseSnapshot(embeddedSnapshot)
+}
+
+// snapshot whose metadata is in use, nil if the metadata comes from the server or from the cache
+func SnapshotInUse() *Snapshot {
+	return snapshotInUse
+}
+
+// check if the error means that the server can't be reached or can't answer
+func unavailable(err error) bool {
+	var network *errs.NetworkError
+	var server *errs.ServerError
+	return errors.As(err, &network) || (errors.As(err, &server) && server.Status >= 500)
+}
+
+// put the options of the embedded snapshot in the app's state, once the server and the cache failed.
+// The snapshot only stands for the server it was taken from, the other servers fail with the cause
+func useEmbeddedSnapshot(state *model.AppState, cause error) error {
+	s, err := EmbeddedSnapshot()
+	if err != nil {
+		return err
+	}
+	if NormalizeURL(s.Server) != serverURL {
+		return cause
+	}
+	if err := parseOptions(s.Metadata, state); err != nil {
+		return &errs.MetadataError{Err: err}
+	}
+	logging.Warn("embedded snapshot used", "server", s.Server, "time", s.Time, "error", cause)
+	snapshotInUse = s
+	metadataTime = s.Time
+	return nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"tacher/src/errs"
+	"tacher/src/model"
+	"testing"
+)
+
+func TestGetOptionsUsesSnapshotOfItsServer(t *testing.T) {
+	// a server that can't answer
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	embedded, err := EmbeddedSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, previousURL := embeddedSnapshot, serverURL
+	defer func() {
+		embeddedSnapshot, snapshotInUse = original, nil
+		SetServerURL(previousURL)
+	}()
+
+	tests := []struct {
+		name     string
+		snapshot string
+		used     bool
+	}{
+		{"snapshot of the server", server.URL, true},
+		{"snapshot of another server", "https://start.spring.io/", false},
+	}
+	for _, test := range tests {
+		snapshotInUse = nil
+		embedded.Server = test.snapshot
+		if embeddedSnapshot, err = json.Marshal(embedded); err != nil {
+			t.Fatal(err)
+		}
+		SetServerURL(server.URL)
+
+		err := GetOptions(new(model.AppState))
+		var unavailable *errs.ServerError
+		switch {
+		case test.used && (err != nil || SnapshotInUse() == nil):
+			t.Errorf("%s: GetOptions() = %v, want the snapshot's options", test.name, err)
+		case !test.used && (!errors.As(err, &unavailable) || unavailable.Status != http.StatusServiceUnavailable):
+			t.Errorf("%s: GetOptions() = %v, want the server's error", test.name, err)
+		case !test.used && SnapshotInUse() != nil:
+			t.Errorf("%s: the snapshot of %s is in use", test.name, test.snapshot)
+		}
+	}
+}
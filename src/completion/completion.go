@@ -0,0 +1,207 @@
+package completion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// hidden command printing the candidates, called by the shell scripts
+const COMPLETE_COMMAND = "__complete"
+
+// scripts of the supported shells, %[1]s is the program's name
+var scripts = map[string]string{
+	"bash": `_%[1]s_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($(%[1]s ` + COMPLETE_COMMAND + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _%[1]s_complete %[1]s
+`,
+	"zsh": `#compdef %[1]s
+_%[1]s() {
+    local -a candidates
+    candidates=("${(@f)$(%[1]s ` + COMPLETE_COMMAND + ` "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ -n "${candidates[1]}" ]]; then
+        compadd -Q -- "${candidates[@]}"
+    else
+        _files
+    fi
+}
+compdef _%[1]s %[1]s
+`,
+	"fish": `function __%[1]s_complete
+    set -l candidates (%[1]s ` + COMPLETE_COMMAND + ` (commandline -opc)[2..-1] (commandline -ct))
+    if test (count $candidates) -gt 0
+        printf '%%s\n' $candidates
+    else
+        __fish_complete_path (commandline -ct)
+    end
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`,
+}
+
+// values of a flag, by the flag's name
+type Values func(flag string) []string
+
+// names of the supported shells, sorted
+func Shells() []string {
+	shells := make([]string, 0, len(scripts))
+	for shell := range scripts {
+		shells = append(shells, shell)
+	}
+	sort.Strings(shells)
+	return shells
+}
+
+// script enabling the completion of the program in the shell
+func Script(shell string, program string) (string, error) {
+	script, found := scripts[shell]
+	if !found {
+		return "", fmt.Errorf("unsupported shell %s, the supported ones are %s", shell, strings.Join(Shells(), ", "))
+	}
+	return fmt.Sprintf(script, program), nil
+}
+
+// complete the last of the words typed after the program's name with the commands, the flags or
+// the flags' values. Values of the list flags are separated by commas. Nothing is returned when
+// the shell should complete file names
+func Complete(app *cli.App, words []string, values Values, lists []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	commands, flags := app.Commands, app.Flags
+
+	// follow the commands typed so far, skipping the flags and their values
+	typed := words[:len(words)-1]
+	for i := 0; i < len(typed); i++ {
+		w := typed[i]
+		if strings.HasPrefix(w, "-") {
+			if f := findFlag(flags, w); f != nil && takesValue(f) && !strings.Contains(w, "=") {
+				i++
+			}
+			continue
+		}
+		for _, c := range commands {
+			if c.HasName(w) {
+				commands, flags = c.Subcommands, c.Flags
+				break
+			}
+		}
+	}
+
+	// bash splits --flag=value in three words
+	current, previous := words[len(words)-1], ""
+	if current == "=" {
+		current = ""
+	}
+	if len(typed) > 0 {
+		previous = typed[len(typed)-1]
+		if previous == "=" && len(typed) > 1 {
+			previous = typed[len(typed)-2]
+		}
+	}
+
+	// value of a flag, either after the flag or after an equal sign
+	if f := findFlag(flags, previous); f != nil && takesValue(f) && !strings.Contains(previous, "=") {
+		return complete(values, f.Names()[0], "", current, lists)
+	}
+	if name, value, found := strings.Cut(current, "="); found && strings.HasPrefix(name, "-") {
+		if f := findFlag(flags, name); f != nil && takesValue(f) {
+			return complete(values, f.Names()[0], name+"=", value, lists)
+		}
+	}
+
+	candidates := make([]string, 0)
+	if strings.HasPrefix(current, "-") {
+		for _, f := range flags {
+			for _, name := range f.Names() {
+				candidates = append(candidates, dashes(name)+name)
+			}
+		}
+		candidates = append(candidates, "--help")
+	} else {
+		for _, c := range commands {
+			if !c.Hidden {
+				candidates = append(candidates, c.Name)
+			}
+		}
+	}
+	return filter(candidates, current)
+}
+
+// complete the value of the flag, the candidates keep the prefix
+func complete(values Values, flag string, prefix string, current string, lists []string) []string {
+	candidates := values(flag)
+	isList := false
+	for _, l := range lists {
+		isList = isList || l == flag
+	}
+	if !isList {
+		return withPrefix(filter(candidates, current), prefix)
+	}
+
+	// only the last value of the list is completed, the chosen ones aren't suggested again
+	idx := strings.LastIndex(current, ",")
+	head, last := current[:idx+1], current[idx+1:]
+	chosen := strings.Split(head, ",")
+	remaining := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		found := false
+		for _, ch := range chosen {
+			found = found || ch == c
+		}
+		if !found {
+			remaining = append(remaining, c)
+		}
+	}
+	return withPrefix(filter(remaining, last), prefix+head)
+}
+
+// find the flag with the given name, written with its dashes and maybe followed by its value
+func findFlag(flags []cli.Flag, word string) cli.Flag {
+	name, _, _ := strings.Cut(strings.TrimLeft(word, "-"), "=")
+	for _, f := range flags {
+		for _, n := range f.Names() {
+			if n == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// check if the flag is followed by a value
+func takesValue(f cli.Flag) bool {
+	doc, isDoc := f.(cli.DocGenerationFlag)
+	return isDoc && doc.TakesValue()
+}
+
+// dashes preceding the flag's name
+func dashes(name string) string {
+	if len(name) == 1 {
+		return "-"
+	}
+	return "--"
+}
+
+// keep the candidates starting with the prefix
+func filter(candidates []string, prefix string) []string {
+	ret := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// prepend the prefix to the candidates
+func withPrefix(candidates []string, prefix string) []string {
+	for i := range candidates {
+		candidates[i] = prefix + candidates[i]
+	}
+	return candidates
+}
@@ -0,0 +1,57 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestComplete(t *testing.T) {
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name: "init",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "language"},
+					&cli.StringFlag{Name: "dependencies"},
+					&cli.BoolFlag{Name: "headless"},
+				},
+			},
+			{Name: "info"},
+			{Name: COMPLETE_COMMAND, Hidden: true},
+		},
+	}
+	values := func(flag string) []string {
+		switch flag {
+		case "language":
+			return []string{"java", "kotlin", "groovy"}
+		case "dependencies":
+			return []string{"web", "webflux", "jdbc"}
+		}
+		return nil
+	}
+	lists := []string{"dependencies"}
+
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{nil, []string{"init", "info"}},
+		{[]string{"in"}, []string{"init", "info"}},
+		{[]string{"ini"}, []string{"init"}},
+		{[]string{"init", "--l"}, []string{"--language"}},
+		{[]string{"init", "--language", ""}, []string{"java", "kotlin", "groovy"}},
+		{[]string{"init", "--language", "k"}, []string{"kotlin"}},
+		{[]string{"init", "--language=g"}, []string{"--language=groovy"}},
+		{[]string{"init", "--language", "=", "j"}, []string{"java"}},
+		{[]string{"init", "--headless", "--dependencies", "web,"}, []string{"web,webflux", "web,jdbc"}},
+		{[]string{"init", "--dependencies", "jdbc,we"}, []string{"jdbc,web", "jdbc,webflux"}},
+		{[]string{"init", "--language", "java", "--h"}, []string{"--headless", "--help"}},
+	}
+	for _, test := range tests {
+		if got := Complete(app, test.words, values, lists); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Complete(%q) = %q, want %q", test.words, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tacher/src/errs"
+	"tacher/src/model"
+	"tacher/src/utils"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const CONFIG_FILE = "config.yaml"
+const PROJECT_CONFIG_FILE = ".tacher.yaml"
+const ENV_PREFIX = "TACHER_"
+
+// layers a value can come from, from the weakest to the strongest. Files, environment
+// variables and flags are followed by their name
+const ORIGIN_BUILT_IN = "built-in"
+const ORIGIN_SERVER = "server"
+const ORIGIN_FILE = "file:"
+const ORIGIN_ENV = "env:"
+const ORIGIN_FLAG = "flag:"
+
+// settings of the project
+const GROUP = "group"
+const ARTIFACT = "artifact"
+const NAME = "name"
+const DESCRIPTION = "description"
+const PACKAGE = "package"
+const TYPE = "type"
+const LANGUAGE = "language"
+const JAVA_VERSION = "javaVersion"
+const BOOT_VERSION = "bootVersion"
+const PACKAGING = "packaging"
+const DEPENDENCIES = "dependencies"
+const MODULES = "modules"
+const PATH = "path"
+
+// settings of tacher
+const SERVER_URL = "server.url"
+const SERVER_TIMEOUT = "server.timeout"
+const SERVER_MERGE = "server.merge"
+const CACHE_DIR = "cache.dir"
+const CACHE_TTL = "cache.ttl"
+const UI_THEME = "ui.theme"
+const UI_OPENER = "ui.opener"
+const UI_EDITOR = "ui.editor"
+const UI_LANGUAGE = "ui.language"
+const POLICY = "policy"
+const LOG_FILE = "log.file"
+const LOG_LEVEL = "log.level"
+
+// prefix of the key bindings' settings, followed by the action
+const UI_KEYS = "ui.keys."
+
+// prefix of the named Spring Initializr instances' URLs, followed by the name
+const SERVERS = "servers."
+
+// setting that can be configured
+type Setting struct {
+	Key         string
+	Description string
+	// value of the built-in layer, if any
+	Default string
+	// lists are written as YAML lists or as comma separated values
+	List bool
+}
+
+// all the settings, except the key bindings and the named servers
+var Settings = []Setting{
+	{Key: GROUP, Description: "group of the project"},
+	{Key: ARTIFACT, Description: "artifact of the project"},
+	{Key: NAME, Description: "name of the project"},
+	{Key: DESCRIPTION, Description: "description of the project"},
+	{Key: PACKAGE, Description: "package name of the project"},
+	{Key: TYPE, Description: "build tool, like maven-project or gradle-project"},
+	{Key: LANGUAGE, Description: "language, like java or kotlin"},
+	{Key: JAVA_VERSION, Description: "Java version"},
+	{Key: BOOT_VERSION, Description: "Spring Boot version"},
+	{Key: PACKAGING, Description: "packaging, jar or war"},
+	{Key: DEPENDENCIES, Description: "IDs of the selected dependencies", List: true},
+	{Key: MODULES, Description: "YAML file listing the modules of a multi-module project"},
+	{Key: PATH, Description: "directory where the project is created"},
+	{Key: POLICY, Description: "policy file that the generated projects must follow"},
+	{Key: SERVER_URL, Description: "URL of Spring Initializr or name of one of the servers", Default: "https://start.spring.io/"},
+	{Key: SERVER_MERGE, Description: "names of the servers whose dependencies are merged with the ones of Spring Initializr", List: true},
+	{Key: SERVER_TIMEOUT, Description: "timeout of the requests to Spring Initializr, 0 means no timeout", Default: "30s"},
+	{Key: CACHE_DIR, Description: "directory caching Spring Initializr's metadata"},
+	{Key: CACHE_TTL, Description: "age after which the cached metadata is fetched again, 0 disables the cache", Default: "24h"},
+	{Key: UI_THEME, Description: "theme of the UI", Default: "dark"},
+	{Key: UI_OPENER, Description: "command opening the dependencies' documentation"},
+	{Key: UI_EDITOR, Description: "command opening the generated project, the first installed of $VISUAL, $EDITOR, code and idea by default"},
+	{Key: UI_LANGUAGE, Description: "language of the UI and of Spring Initializr's descriptions, from LC_ALL, LC_MESSAGES or LANG by default"},
+	{Key: LOG_FILE, Description: "file where the log is appended, nothing is logged without it"},
+	{Key: LOG_LEVEL, Description: "lowest level logged: debug, info, warn or error", Default: "info"},
+}
+
+// value of a setting and the layer it comes from
+type value struct {
+	value  string
+	origin string
+}
+
+// settings merged from all the layers
+type Config struct {
+	values map[string]value
+}
+
+// load the built-in values, the user's configuration file, the project's one found walking up
+// from the current directory and the environment variables, in this order of precedence
+func Load() (*Config, error) {
+	c := &Config{values: make(map[string]value)}
+	for _, s := range Settings {
+		if s.Default != "" {
+			c.values[s.Key] = value{s.Default, ORIGIN_BUILT_IN}
+		}
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		c.values[CACHE_DIR] = value{filepath.Join(dir, "tacher"), ORIGIN_BUILT_IN}
+	}
+
+	files := make([]string, 0, 2)
+	if file, err := UserFile(); err == nil {
+		files = append(files, file)
+	}
+	if file, found := findProjectFile(); found {
+		files = append(files, file)
+	}
+	for _, file := range files {
+		if err := c.loadFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	c.loadEnv()
+	return c, nil
+}
+
+// path of the configuration file in tacher's configuration directory
+func UserFile() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, CONFIG_FILE), nil
+}
+
+// find the project's configuration file in the current directory or in its parents
+func findProjectFile() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		file := filepath.Join(dir, PROJECT_CONFIG_FILE)
+		if _, err := os.Stat(file); err == nil {
+			return file, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// load the settings of a configuration file, nothing happens if it doesn't exist
+func (c *Config) loadFile(file string) error {
+	content, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(content, &tree); err != nil {
+		return errs.Input("can't parse %s: %w", file, err)
+	}
+	flat := make(map[string]string)
+	flatten("", tree, flat)
+	for key, v := range flat {
+		if err := c.Set(key, v, ORIGIN_FILE+file); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// flatten the YAML tree in dotted keys, lists become comma separated values
+func flatten(prefix string, node interface{}, flat map[string]string) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			flatten(prefix+k+".", v, flat)
+		}
+	case []interface{}:
+		flat[strings.TrimSuffix(prefix, ".")] = strings.Join(utils.Map(n, func(e interface{}) string { return fmt.Sprint(e) }), ",")
+	case nil:
+		flat[strings.TrimSuffix(prefix, ".")] = ""
+	default:
+		flat[strings.TrimSuffix(prefix, ".")] = fmt.Sprint(n)
+	}
+}
+
+// load the settings of the environment variables
+func (c *Config) loadEnv() {
+	for _, s := range Settings {
+		if v, found := os.LookupEnv(EnvName(s.Key)); found {
+			c.values[s.Key] = value{v, ORIGIN_ENV + EnvName(s.Key)}
+		}
+	}
+	for _, prefix := range []string{UI_KEYS, SERVERS} {
+		envPrefix := EnvName(prefix)
+		for _, env := range os.Environ() {
+			name, v, _ := strings.Cut(env, "=")
+			if strings.HasPrefix(name, envPrefix) && name != envPrefix {
+				suffix := strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, envPrefix)), "_", "-")
+				c.values[prefix+suffix] = value{v, ORIGIN_ENV + name}
+			}
+		}
+	}
+}
+
+// name of the environment variable of a setting, like TACHER_JAVA_VERSION for javaVersion
+func EnvName(key string) string {
+	var b strings.Builder
+	b.WriteString(ENV_PREFIX)
+	for i, r := range key {
+		switch {
+		case r == '.' || r == '-':
+			b.WriteRune('_')
+		case unicode.IsUpper(r) && i > 0:
+			b.WriteRune('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// find the setting with the given key
+func Find(key string) (Setting, bool) {
+	if strings.HasPrefix(key, UI_KEYS) && len(key) > len(UI_KEYS) {
+		return Setting{Key: key, Description: "key bound to the " + strings.TrimPrefix(key, UI_KEYS) + " action"}, true
+	}
+	if strings.HasPrefix(key, SERVERS) && len(key) > len(SERVERS) {
+		return Setting{Key: key, Description: "URL of the " + strings.TrimPrefix(key, SERVERS) + " Spring Initializr"}, true
+	}
+	idx, found := utils.Find(Settings, func(s Setting) bool { return s.Key == key })
+	if !found {
+		return Setting{}, false
+	}
+	return Settings[idx], true
+}
+
+// set the value of a setting, overriding the one of the weaker layers
+func (c *Config) Set(key string, v string, origin string) error {
+	if _, found := Find(key); !found {
+		return errs.Input("unknown setting %s", key)
+	}
+	c.values[key] = value{v, origin}
+	return nil
+}
+
+// set the default values that Spring initializer suggests, they only replace the built-in values
+func (c *Config) SetServerDefaults(state *model.AppState) {
+	defaults := map[string]string{
+		GROUP:       state.DefaultGroupId,
+		ARTIFACT:    state.DefaultArtifactId,
+		NAME:        state.DefaultName,
+		DESCRIPTION: state.DefaultDescription,
+		PACKAGE:     state.DefaultPackageName,
+	}
+	if len(state.SpringBuildTools) > state.DefaultSpringBuildTool {
+		defaults[TYPE] = state.SpringBuildTools[state.DefaultSpringBuildTool].ID
+	}
+	if len(state.Languages) > state.DefaultLanguage {
+		defaults[LANGUAGE] = state.Languages[state.DefaultLanguage].ID
+	}
+	if len(state.JavaVersions) > state.DefaultJavaVersion {
+		defaults[JAVA_VERSION] = state.JavaVersions[state.DefaultJavaVersion].ID
+	}
+	if len(state.SpringVersions) > state.DefaultSpringVersion {
+		defaults[BOOT_VERSION] = state.SpringVersions[state.DefaultSpringVersion].ID
+	}
+	if len(state.Packaging) > state.DefaultPackaging {
+		defaults[PACKAGING] = state.Packaging[state.DefaultPackaging].ID
+	}
+	for key, v := range defaults {
+		if current, found := c.values[key]; v != "" && (!found || current.origin == ORIGIN_BUILT_IN) {
+			c.values[key] = value{v, ORIGIN_SERVER}
+		}
+	}
+}
+
+// URL of a server given by its name or by its URL
+func (c *Config) ResolveServer(server string) (string, error) {
+	if url, found := c.values[SERVERS+server]; found {
+		return url.value, nil
+	}
+	if strings.Contains(server, "://") {
+		return server, nil
+	}
+	return "", errs.Input("unknown server %s, it's neither a URL nor the name of one of the servers", server)
+}
+
+// value of the setting, empty if it isn't set
+func (c *Config) Get(key string) string {
+	return c.values[key].value
+}
+
+// layer the setting's value comes from, empty if it isn't set
+func (c *Config) Origin(key string) string {
+	return c.values[key].origin
+}
+
+// values of a list setting
+func (c *Config) List(key string) []string {
+	return splitList(c.Get(key))
+}
+
+// split comma separated values, the empty ones are dropped
+func splitList(list string) []string {
+	ret := make([]string, 0)
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// value of a duration setting, zero if it isn't set. A bare number is a number of seconds
+func (c *Config) Duration(key string) (time.Duration, error) {
+	v := strings.TrimSpace(c.Get(key))
+	if v == "" || v == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		if d, err = time.ParseDuration(v + "s"); err != nil {
+			return 0, errs.Input("invalid duration %q for %s", v, key)
+		}
+	}
+	return d, nil
+}
+
+// settings with the given prefix, keyed without the prefix
+func (c *Config) Prefixed(prefix string) map[string]string {
+	ret := make(map[string]string)
+	for key, v := range c.values {
+		if strings.HasPrefix(key, prefix) {
+			ret[strings.TrimPrefix(key, prefix)] = v.value
+		}
+	}
+	return ret
+}
+
+// keys of the settings that are set, sorted
+func (c *Config) Keys() []string {
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// project's data of the settings, either the ones coming from flags or the ones coming from
+// the other layers. Dependencies only have their ID
+func (c *Config) AppData(fromFlags bool) *model.AppData {
+	get := func(key string) string {
+		if strings.HasPrefix(c.Origin(key), ORIGIN_FLAG) != fromFlags || c.Origin(key) == ORIGIN_SERVER {
+			return ""
+		}
+		return c.Get(key)
+	}
+	data := &model.AppData{
+		Group:             get(GROUP),
+		Artifact:          get(ARTIFACT),
+		Name:              get(NAME),
+		Description:       get(DESCRIPTION),
+		Pkg:               get(PACKAGE),
+		SpringBuildTool:   get(TYPE),
+		Language:          get(LANGUAGE),
+		JavaVersion:       get(JAVA_VERSION),
+		SpringBootVersion: get(BOOT_VERSION),
+		Packaging:         get(PACKAGING),
+		Path:              utils.ExpandPath(get(PATH)),
+	}
+	if get(DEPENDENCIES) != "" {
+		for _, id := range c.List(DEPENDENCIES) {
+			data.Dependencies = append(data.Dependencies, model.ValueWithDesc{ID: id})
+		}
+	}
+	return data
+}
+
+// write the setting in the configuration file, creating it if it doesn't exist. The file's
+// comments aren't kept
+func Write(file string, key string, v string) error {
+	setting, found := Find(key)
+	if !found {
+		return errs.Input("unknown setting %s", key)
+	}
+	tree := make(map[string]interface{})
+	content, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := yaml.Unmarshal(content, &tree); err != nil {
+		return errs.Input("can't parse %s: %w", file, err)
+	}
+	if tree == nil {
+		tree = make(map[string]interface{})
+	}
+
+	// walk the nested maps down to the setting's parent
+	path := strings.Split(key, ".")
+	node := tree
+	for _, name := range path[:len(path)-1] {
+		child, isMap := node[name].(map[string]interface{})
+		if !isMap {
+			child = make(map[string]interface{})
+			node[name] = child
+		}
+		node = child
+	}
+	var newValue interface{} = v
+	if setting.List {
+		newValue = splitList(v)
+	}
+	node[path[len(path)-1]] = newValue
+
+	content, err = yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0644)
+}
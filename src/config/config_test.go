@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"tacher/src/model"
+	"testing"
+)
+
+// layers that can set a value, from the weakest to the strongest
+const (
+	builtIn = iota
+	server
+	userFile
+	projectFile
+	env
+	flag
+)
+
+// write a configuration file setting the Java version
+func writeJavaVersion(t *testing.T, file string, javaVersion string) {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(JAVA_VERSION+": \""+javaVersion+"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// load the configuration from a new home and a new project directory, the Java version is set in
+// every layer up to the strongest one
+func loadLayers(t *testing.T, strongest int) (*Config, string, string) {
+	home := t.TempDir()
+	project, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(EnvName(JAVA_VERSION), "")
+	os.Unsetenv(EnvName(JAVA_VERSION))
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if strongest >= userFile {
+		writeJavaVersion(t, filepath.Join(home, ".config", "tacher", CONFIG_FILE), "17")
+	}
+	if strongest >= projectFile {
+		writeJavaVersion(t, filepath.Join(project, PROJECT_CONFIG_FILE), "19")
+	}
+	if strongest >= env {
+		t.Setenv(EnvName(JAVA_VERSION), "20")
+	}
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strongest >= server {
+		c.SetServerDefaults(&model.AppState{JavaVersions: []model.Value{{ID: "11"}}})
+	}
+	if strongest >= flag {
+		c.Set(JAVA_VERSION, "21", ORIGIN_FLAG+"--java")
+	}
+	return c, home, project
+}
+
+func TestLayers(t *testing.T) {
+	// the Java version has no built-in value, give it one
+	for i := range Settings {
+		if Settings[i].Key == JAVA_VERSION {
+			Settings[i].Default = "8"
+			defer func(i int) { Settings[i].Default = "" }(i)
+		}
+	}
+
+	tests := []struct {
+		strongest int
+		want      string
+		origin    func(home string, project string) string
+		// value of the project's data from the flags and from the other layers
+		fromFlags  string
+		fromOthers string
+	}{
+		{builtIn, "8", func(string, string) string { return ORIGIN_BUILT_IN }, "", "8"},
+		// the server's defaults are filled in the project's data later
+		{server, "11", func(string, string) string { return ORIGIN_SERVER }, "", ""},
+		{userFile, "17", func(home string, _ string) string {
+			return ORIGIN_FILE + filepath.Join(home, ".config", "tacher", CONFIG_FILE)
+		}, "", "17"},
+		{projectFile, "19", func(_ string, project string) string {
+			return ORIGIN_FILE + filepath.Join(project, PROJECT_CONFIG_FILE)
+		}, "", "19"},
+		{env, "20", func(string, string) string { return ORIGIN_ENV + "TACHER_JAVA_VERSION" }, "", "20"},
+		{flag, "21", func(string, string) string { return ORIGIN_FLAG + "--java" }, "21", ""},
+	}
+	for _, test := range tests {
+		c, home, project := loadLayers(t, test.strongest)
+		origin := test.origin(home, project)
+		if got := c.Get(JAVA_VERSION); got != test.want || c.Origin(JAVA_VERSION) != origin {
+			t.Errorf("Java version = %s from %s, want %s from %s", got, c.Origin(JAVA_VERSION), test.want, origin)
+		}
+		if got := c.AppData(true).JavaVersion; got != test.fromFlags {
+			t.Errorf("%s: Java version of the flags = %q, want %q", origin, got, test.fromFlags)
+		}
+		if got := c.AppData(false).JavaVersion; got != test.fromOthers {
+			t.Errorf("%s: Java version of the other layers = %q, want %q", origin, got, test.fromOthers)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"tacher/src/model"
+)
+
+// values added to and removed from a list of the metadata
+type Delta struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// value of the metadata that changed
+type Change struct {
+	// default or dependency that changed
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// changes between two versions of Spring initializer's metadata
+type Changes struct {
+	BootVersions Delta `json:"bootVersions"`
+	JavaVersions Delta `json:"javaVersions"`
+	// dependencies by ID
+	Dependencies Delta `json:"dependencies"`
+	Categories   Delta `json:"categories"`
+	// defaults named like the metadata's fields
+	Defaults []Change `json:"defaults"`
+	// ranges of Spring Boot versions that the dependencies are compatible with
+	Ranges []Change `json:"ranges"`
+}
+
+// compare the old metadata with the new one
+func Compare(old *model.AppState, new *model.AppState) *Changes {
+	ids := func(values []model.Value) []string {
+		ret := make([]string, 0, len(values))
+		for _, v := range values {
+			ret = append(ret, v.ID)
+		}
+		return ret
+	}
+	oldDeps, newDeps := dependencies(old), dependencies(new)
+	c := &Changes{
+		BootVersions: compareLists(ids(old.SpringVersions), ids(new.SpringVersions)),
+		JavaVersions: compareLists(ids(old.JavaVersions), ids(new.JavaVersions)),
+		Dependencies: compareLists(sortedKeys(oldDeps), sortedKeys(newDeps)),
+		Categories:   compareLists(sortedKeys(old.Dependency), sortedKeys(new.Dependency)),
+		Defaults:     make([]Change, 0),
+		Ranges:       make([]Change, 0),
+	}
+
+	oldDefaults, newDefaults := defaults(old), defaults(new)
+	for _, name := range sortedKeys(newDefaults) {
+		if oldDefaults[name] != newDefaults[name] {
+			c.Defaults = append(c.Defaults, Change{Name: name, Old: oldDefaults[name], New: newDefaults[name]})
+		}
+	}
+	for _, id := range sortedKeys(newDeps) {
+		if d, found := oldDeps[id]; found && d.VersionRange != newDeps[id].VersionRange {
+			c.Ranges = append(c.Ranges, Change{Name: id, Old: d.VersionRange, New: newDeps[id].VersionRange})
+		}
+	}
+	return c
+}
+
+// values that are in only one of the lists, in the lists' order
+func compareLists(old []string, new []string) Delta {
+	missingFrom := func(list []string, others []string) []string {
+		set := make(map[string]bool, len(others))
+		for _, o := range others {
+			set[o] = true
+		}
+		ret := make([]string, 0)
+		for _, v := range list {
+			if !set[v] {
+				ret = append(ret, v)
+			}
+		}
+		return ret
+	}
+	return Delta{Added: missingFrom(new, old), Removed: missingFrom(old, new)}
+}
+
+// dependencies of all the categories by ID
+func dependencies(state *model.AppState) map[string]model.ValueWithDesc {
+	ret := make(map[string]model.ValueWithDesc)
+	for _, category := range state.Dependency {
+		for _, d := range category {
+			ret[d.ID] = d
+		}
+	}
+	return ret
+}
+
+// default values of the metadata, named like its fields
+func defaults(state *model.AppState) map[string]string {
+	ret := map[string]string{
+		"groupId":     state.DefaultGroupId,
+		"artifactId":  state.DefaultArtifactId,
+		"version":     state.DefaultVersion,
+		"name":        state.DefaultName,
+		"description": state.DefaultDescription,
+		"packageName": state.DefaultPackageName,
+	}
+	if state.DefaultSpringBuildTool < len(state.SpringBuildTools) {
+		ret["type"] = state.SpringBuildTools[state.DefaultSpringBuildTool].ID
+	}
+	selected := map[string]struct {
+		values []model.Value
+		idx    int
+	}{
+		"packaging":   {state.Packaging, state.DefaultPackaging},
+		"javaVersion": {state.JavaVersions, state.DefaultJavaVersion},
+		"language":    {state.Languages, state.DefaultLanguage},
+		"bootVersion": {state.SpringVersions, state.DefaultSpringVersion},
+	}
+	for name, s := range selected {
+		if s.idx < len(s.values) {
+			ret[name] = s.values[s.idx].ID
+		}
+	}
+	return ret
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// check if nothing changed
+func (c *Changes) IsEmpty() bool {
+	return len(c.BootVersions.Added)+len(c.BootVersions.Removed)+len(c.JavaVersions.Added)+len(c.JavaVersions.Removed)+
+		len(c.Dependencies.Added)+len(c.Dependencies.Removed)+len(c.Categories.Added)+len(c.Categories.Removed)+
+		len(c.Defaults)+len(c.Ranges) == 0
+}
+
+// write the changes for humans, one section by kind of change. Nothing is written without changes
+func (c *Changes) Write(w io.Writer) {
+	deltas := []struct {
+		title string
+		delta Delta
+	}{
+		{"Spring Boot versions", c.BootVersions},
+		{"Java versions", c.JavaVersions},
+		{"Categories", c.Categories},
+		{"Dependencies", c.Dependencies},
+	}
+	for _, d := range deltas {
+		if len(d.delta.Added)+len(d.delta.Removed) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, d.title)
+		for _, v := range d.delta.Added {
+			fmt.Fprintf(w, "  + %s\n", v)
+		}
+		for _, v := range d.delta.Removed {
+			fmt.Fprintf(w, "  - %s\n", v)
+		}
+	}
+	changes := []struct {
+		title   string
+		changes []Change
+	}{
+		{"Defaults", c.Defaults},
+		{"Compatibility ranges", c.Ranges},
+	}
+	for _, ch := range changes {
+		if len(ch.changes) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, ch.title)
+		for _, change := range ch.changes {
+			fmt.Fprintf(w, "  %s: %s -> %s\n", change.Name, orNone(change.Old), orNone(change.New))
+		}
+	}
+}
+
+func orNone(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return v
+}
+
+// the added Spring Boot versions, Java versions and dependencies, for a short banner. Empty if
+// none were added
+func (c *Changes) Highlights() []string {
+	ret := make([]string, 0)
+	for _, v := range c.BootVersions.Added {
+		ret = append(ret, "Spring Boot "+v)
+	}
+	for _, v := range c.JavaVersions.Added {
+		ret = append(ret, "Java "+v)
+	}
+	if len(c.Dependencies.Added) > 0 {
+		ret = append(ret, strings.Join(c.Dependencies.Added, ", "))
+	}
+	return ret
+}
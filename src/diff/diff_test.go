@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"reflect"
+	"tacher/src/model"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	old := &model.AppState{
+		DefaultGroupId: "com.example",
+		SpringVersions: []model.Value{{ID: "3.0.0"}, {ID: "2.7.6"}},
+		JavaVersions:   []model.Value{{ID: "17"}, {ID: "11"}},
+		Dependency: map[string][]model.ValueWithDesc{
+			"Web": {{ID: "web", VersionRange: "[2.7.0,)"}, {ID: "jersey"}},
+			"SQL": {{ID: "jdbc"}},
+		},
+	}
+	new := &model.AppState{
+		DefaultGroupId:       "org.example",
+		SpringVersions:       []model.Value{{ID: "3.1.0"}, {ID: "3.0.0"}},
+		DefaultSpringVersion: 1,
+		JavaVersions:         []model.Value{{ID: "17"}, {ID: "11"}},
+		Dependency: map[string][]model.ValueWithDesc{
+			"Web": {{ID: "web", VersionRange: "[3.0.0,)"}, {ID: "jersey"}, {ID: "graphql"}},
+			"I/O": {{ID: "batch"}},
+		},
+	}
+
+	c := Compare(old, new)
+	if want := (Delta{Added: []string{"3.1.0"}, Removed: []string{"2.7.6"}}); !reflect.DeepEqual(c.BootVersions, want) {
+		t.Errorf("boot versions = %+v, want %+v", c.BootVersions, want)
+	}
+	if len(c.JavaVersions.Added)+len(c.JavaVersions.Removed) != 0 {
+		t.Errorf("java versions = %+v, want no change", c.JavaVersions)
+	}
+	if want := (Delta{Added: []string{"batch", "graphql"}, Removed: []string{"jdbc"}}); !reflect.DeepEqual(c.Dependencies, want) {
+		t.Errorf("dependencies = %+v, want %+v", c.Dependencies, want)
+	}
+	if want := (Delta{Added: []string{"I/O"}, Removed: []string{"SQL"}}); !reflect.DeepEqual(c.Categories, want) {
+		t.Errorf("categories = %+v, want %+v", c.Categories, want)
+	}
+	// the default boot version is still 3.0.0, at another index
+	if want := []Change{{Name: "groupId", Old: "com.example", New: "org.example"}}; !reflect.DeepEqual(c.Defaults, want) {
+		t.Errorf("defaults = %+v, want %+v", c.Defaults, want)
+	}
+	if want := []Change{{Name: "web", Old: "[2.7.0,)", New: "[3.0.0,)"}}; !reflect.DeepEqual(c.Ranges, want) {
+		t.Errorf("ranges = %+v, want %+v", c.Ranges, want)
+	}
+	if c.IsEmpty() {
+		t.Error("IsEmpty() = true, want false")
+	}
+}
+
+func TestCompareSame(t *testing.T) {
+	state := &model.AppState{
+		SpringVersions: []model.Value{{ID: "3.0.0"}},
+		Dependency:     map[string][]model.ValueWithDesc{"Web": {{ID: "web"}}},
+	}
+	if c := Compare(state, state); !c.IsEmpty() {
+		t.Errorf("Compare() = %+v, want no change", c)
+	}
+}
@@ -0,0 +1,257 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// exit codes of tacher, one for each kind of error
+const EXIT_OK = 0
+const EXIT_INTERNAL = 1
+const EXIT_INPUT = 2
+const EXIT_NETWORK = 3
+const EXIT_SERVER = 4
+const EXIT_METADATA = 5
+const EXIT_FILESYSTEM = 6
+const EXIT_POLICY = 7
+const EXIT_HOOK = 8
+
+// kinds of the errors, telling the scripts what went wrong without parsing the messages
+const KIND_INTERNAL = "internal"
+const KIND_INPUT = "input"
+const KIND_NETWORK = "network"
+const KIND_SERVER = "server"
+const KIND_METADATA = "metadata"
+const KIND_FILESYSTEM = "filesystem"
+const KIND_POLICY = "policy"
+const KIND_HOOK = "hook"
+
+// fields of the project that an error can be about, named like the settings
+const FIELD_TYPE = "type"
+const FIELD_LANGUAGE = "language"
+const FIELD_BOOT_VERSION = "bootVersion"
+const FIELD_JAVA_VERSION = "javaVersion"
+const FIELD_PACKAGING = "packaging"
+const FIELD_DEPENDENCIES = "dependencies"
+
+// Spring initializer's server can't be reached
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// request rejected by Spring initializer's server
+type ServerError struct {
+	// HTTP status of the response
+	Status int
+	// message sent by the server, empty if there's none
+	Message string
+	// endpoint that failed, empty for the generated files
+	Endpoint string
+	// field of the project that the server rejected and its value, empty if the message doesn't tell
+	Field string
+	Value string
+}
+
+// messages of Spring initializer about a field of the project, the value is the first group
+var rejectedFields = []struct {
+	field   string
+	pattern *regexp.Regexp
+}{
+	{FIELD_DEPENDENCIES, regexp.MustCompile(`(?i)dependency '([^']+)'`)},
+	{FIELD_BOOT_VERSION, regexp.MustCompile(`(?i)spring boot version '([^']+)'`)},
+	{FIELD_JAVA_VERSION, regexp.MustCompile(`(?i)java version '([^']+)'`)},
+	{FIELD_TYPE, regexp.MustCompile(`(?i)unknown type '([^']+)'`)},
+	{FIELD_LANGUAGE, regexp.MustCompile(`(?i)unknown language '([^']+)'`)},
+	{FIELD_PACKAGING, regexp.MustCompile(`(?i)unknown packaging '([^']+)'`)},
+}
+
+// build the server's error, finding the field it rejected from the message
+func NewServerError(status int, message string, endpoint string) *ServerError {
+	e := &ServerError{Status: status, Message: message, Endpoint: endpoint}
+	for _, r := range rejectedFields {
+		if match := r.pattern.FindStringSubmatch(message); match != nil {
+			e.Field, e.Value = r.field, match[1]
+			break
+		}
+	}
+	return e
+}
+
+func (e *ServerError) Error() string {
+	msg := fmt.Sprintf("unexpected response code [%d]", e.Status)
+	if e.Endpoint != "" {
+		msg += " from " + e.Endpoint
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("%s. Message: [%s]", msg, e.Message)
+	}
+	return msg + "."
+}
+
+// Spring initializer's metadata can't be parsed
+type MetadataError struct {
+	Err error
+}
+
+func (e *MetadataError) Error() string {
+	return fmt.Sprintf("can't parse Spring initializer's metadata: %s", e.Err)
+}
+
+func (e *MetadataError) Unwrap() error {
+	return e.Err
+}
+
+// invalid value given by the user, in the flags, the configuration or the files
+type InputError struct {
+	// field of the project that is invalid and its value, empty if the error isn't about a field
+	Field string
+	Value string
+	Err   error
+}
+
+// build an input error from a message, like fmt.Errorf
+func Input(format string, a ...any) *InputError {
+	return &InputError{Err: fmt.Errorf(format, a...)}
+}
+
+func (e *InputError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// the project can't be written because of what's already on the disk
+type ConflictError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("can't write %s: %s", e.Path, e.Err)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// the project violates the rules of the policy
+type ViolationError struct {
+	Violations []string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("the project violates the policy:\n  %s", strings.Join(e.Violations, "\n  "))
+}
+
+// a hook run after the generation failed, the project itself was generated
+type HookError struct {
+	// name of the hook
+	Hook string
+	// exit code of the hook's command, -1 if it couldn't be started
+	ExitCode int
+	Err      error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("hook %s failed: %s", e.Hook, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// projects of a batch that weren't generated
+type BatchError struct {
+	Failed int
+	Total  int
+	// errors of the projects that failed, the skipped ones have none
+	Errs []error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d projects were not generated", e.Failed, e.Total)
+}
+
+// kind and exit code shared by the projects' errors, the internal ones if they differ
+func (e *BatchError) classify() (string, int) {
+	kind, code := "", EXIT_OK
+	for _, err := range e.Errs {
+		k, c := Classify(err)
+		if code != EXIT_OK && c != code {
+			return KIND_INTERNAL, EXIT_INTERNAL
+		}
+		kind, code = k, c
+	}
+	if code == EXIT_OK {
+		return KIND_INTERNAL, EXIT_INTERNAL
+	}
+	return kind, code
+}
+
+// kind and exit code of the error, from the typed errors it wraps
+func Classify(err error) (string, int) {
+	var batch *BatchError
+	var network *NetworkError
+	var server *ServerError
+	var metadata *MetadataError
+	var input *InputError
+	var conflict *ConflictError
+	var violations *ViolationError
+	var hook *HookError
+	var path *fs.PathError
+	var link *os.LinkError
+	switch {
+	case err == nil:
+		return "", EXIT_OK
+	case errors.As(err, &batch):
+		return batch.classify()
+	case errors.As(err, &server):
+		return KIND_SERVER, EXIT_SERVER
+	case errors.As(err, &network):
+		return KIND_NETWORK, EXIT_NETWORK
+	case errors.As(err, &metadata):
+		return KIND_METADATA, EXIT_METADATA
+	case errors.As(err, &violations):
+		return KIND_POLICY, EXIT_POLICY
+	case errors.As(err, &hook):
+		return KIND_HOOK, EXIT_HOOK
+	case errors.As(err, &input):
+		return KIND_INPUT, EXIT_INPUT
+	case errors.As(err, &conflict), errors.As(err, &path), errors.As(err, &link):
+		return KIND_FILESYSTEM, EXIT_FILESYSTEM
+	}
+	return KIND_INTERNAL, EXIT_INTERNAL
+}
+
+// exit code of the error
+func ExitCode(err error) int {
+	_, code := Classify(err)
+	return code
+}
+
+// field of the project that the error is about and its value, empty if there's none
+func FieldOf(err error) (string, string) {
+	var server *ServerError
+	var input *InputError
+	if errors.As(err, &server) {
+		return server.Field, server.Value
+	}
+	if errors.As(err, &input) {
+		return input.Field, input.Value
+	}
+	return "", ""
+}
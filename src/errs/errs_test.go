@@ -0,0 +1,39 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind string
+		code int
+	}{
+		{"none", nil, "", EXIT_OK},
+		{"untyped", errors.New("boom"), KIND_INTERNAL, EXIT_INTERNAL},
+		{"input", Input("unknown setting %s", "foo"), KIND_INPUT, EXIT_INPUT},
+		{"network", &NetworkError{Err: errors.New("connection refused")}, KIND_NETWORK, EXIT_NETWORK},
+		{"server", NewServerError(400, "Invalid Spring Boot version '1.0.0'", ""), KIND_SERVER, EXIT_SERVER},
+		{"metadata", &MetadataError{Err: errors.New("unexpected end of JSON input")}, KIND_METADATA, EXIT_METADATA},
+		{"conflict", &ConflictError{Path: "demo/pom.xml", Err: fs.ErrExist}, KIND_FILESYSTEM, EXIT_FILESYSTEM},
+		{"file system", &fs.PathError{Op: "open", Path: "demo", Err: fs.ErrPermission}, KIND_FILESYSTEM, EXIT_FILESYSTEM},
+		{"policy", &ViolationError{Violations: []string{"Java 11 is below 17"}}, KIND_POLICY, EXIT_POLICY},
+		{"hook", &HookError{Hook: "format", ExitCode: 1, Err: errors.New("exit status 1")}, KIND_HOOK, EXIT_HOOK},
+		// a hook whose command can't be found still fails as a hook
+		{"hook not started", &HookError{Hook: "format", ExitCode: -1, Err: &fs.PathError{Op: "exec", Path: "fmt", Err: fs.ErrNotExist}}, KIND_HOOK, EXIT_HOOK},
+		{"wrapped", fmt.Errorf("can't generate: %w", &NetworkError{Err: errors.New("timeout")}), KIND_NETWORK, EXIT_NETWORK},
+		{"batch of the same kind", &BatchError{Failed: 2, Total: 3, Errs: []error{&ViolationError{}, &ViolationError{}}}, KIND_POLICY, EXIT_POLICY},
+		{"batch of different kinds", &BatchError{Failed: 2, Total: 3, Errs: []error{&ViolationError{}, Input("bad")}}, KIND_INTERNAL, EXIT_INTERNAL},
+	}
+	for _, test := range tests {
+		kind, code := Classify(test.err)
+		if kind != test.kind || code != test.code {
+			t.Errorf("%s: Classify() = %s, %d, want %s, %d", test.name, kind, code, test.kind, test.code)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"tacher/src/buildfile"
+	"tacher/src/client"
+	"tacher/src/errs"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"tacher/src/overlay"
+	"tacher/src/version"
+	"time"
+)
+
+// options of the generation, they are not part of the project's data
+type Options struct {
+	// directories whose files are rendered in the generated project
+	Overlays []string
+}
+
+// generates the project from the given data, either as a single project or, when modules
+// are defined, as a multi-module project. The overlays are applied once the project is generated
+func Generate(data *model.AppData, opts Options) error {
+	start := time.Now()
+	logging.Info("generating the project", "artifact", data.Artifact, "path", data.Path, "type", data.SpringBuildTool,
+		"bootVersion", data.SpringBootVersion, "modules", len(data.Modules), "overlays", len(opts.Overlays))
+	var err error
+	if len(data.Modules) > 0 {
+		err = generateModules(data)
+	} else {
+		err = generateProject(data, data.Path)
+	}
+	if err == nil {
+		err = overlay.Apply(opts.Overlays, data)
+	}
+	if err != nil {
+		logging.Error("the project can't be generated", "artifact", data.Artifact, "duration", time.Since(start), "error", err)
+		return err
+	}
+	logging.Info("project generated", "artifact", data.Artifact, "duration", time.Since(start))
+	return nil
+}
+
+// generates a project with Spring initializer in the given directory, then adds the dependencies
+// of the local catalogs to its build file
+func generateProject(data *model.AppData, path string) error {
+	standard, custom, err := splitDependencies(data.Dependencies, data.SpringBootVersion)
+	if err != nil {
+		return err
+	}
+
+	logging.Debug("dependencies split", "artifact", data.Artifact, "standard", len(standard), "custom", len(custom))
+	project := *data
+	project.Dependencies = standard
+	project.Modules = nil
+	project.Path = path
+	if err := client.Generate(&project); err != nil {
+		return err
+	}
+
+	return buildfile.AddDependencies(filepath.Join(path, data.Artifact), custom)
+}
+
+// gets the build file that Spring initializer generates for the project's data, with the
+// dependencies of the local catalogs added as in the generated project
+func PreviewBuildFile(data *model.AppData) (string, error) {
+	standard, custom, err := splitDependencies(data.Dependencies, data.SpringBootVersion)
+	if err != nil {
+		return "", err
+	}
+	project := *data
+	project.Dependencies = standard
+	build, err := client.GetBuildFile(&project)
+	if err != nil {
+		return "", err
+	}
+	name := buildfile.GRADLE_BUILD
+	if strings.HasPrefix(data.SpringBuildTool, "maven") {
+		name = buildfile.MAVEN_POM
+	}
+	return buildfile.AddDependenciesTo(name, build, custom)
+}
+
+// split the dependencies known by Spring initializer from the ones of the local catalogs, which
+// must be compatible with the given Spring Boot version
+func splitDependencies(deps []model.ValueWithDesc, bootVersion string) ([]model.ValueWithDesc, []model.CustomDependency, error) {
+	standard := make([]model.ValueWithDesc, 0, len(deps))
+	custom := make([]model.CustomDependency, 0)
+	for _, d := range deps {
+		if d.Custom == nil {
+			standard = append(standard, d)
+			continue
+		}
+		if d.Custom.VersionRange != "" {
+			compatible, err := version.InRange(bootVersion, d.Custom.VersionRange)
+			if err != nil {
+				return nil, nil, &errs.InputError{Field: errs.FIELD_DEPENDENCIES, Value: d.ID, Err: fmt.Errorf("can't check %s's compatibility: %w", d.ID, err)}
+			}
+			if !compatible {
+				return nil, nil, &errs.InputError{Field: errs.FIELD_DEPENDENCIES, Value: d.ID, Err: fmt.Errorf("%s requires Spring Boot %s", d.Name, d.Custom.VersionRange)}
+			}
+		}
+		custom = append(custom, *d.Custom)
+	}
+	return standard, custom, nil
+}
+
+// map the given dependency IDs on the dependencies offered by Spring initializer
+func ResolveDependencies(ids []string, state *model.AppState) ([]model.ValueWithDesc, error) {
+	byID := make(map[string]model.ValueWithDesc)
+	for _, deps := range state.Dependency {
+		for _, d := range deps {
+			byID[d.ID] = d
+		}
+	}
+
+	ret := make([]model.ValueWithDesc, 0, len(ids))
+	for _, id := range ids {
+		dep, found := byID[id]
+		if !found {
+			return nil, &errs.InputError{Field: errs.FIELD_DEPENDENCIES, Value: id, Err: fmt.Errorf("unknown dependency %s", id)}
+		}
+		ret = append(ret, dep)
+	}
+	return ret, nil
+}
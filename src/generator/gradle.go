@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"tacher/src/model"
+)
+
+// builds generated by Spring initializer are indented with tabs, top level blocks start at the beginning of a line
+var (
+	gradlePluginVersion = regexp.MustCompile(`(?m)^\t((?:id|kotlin)\s*\(?\s*["'][^"']+["']\s*\)?)\s+version\s+(["'][^"']+["'])`)
+	gradleCoordinates   = regexp.MustCompile(`(?m)^(group|version)\s*=.*\n`)
+	gradleExtra         = regexp.MustCompile(`(?m)^extra\[.*\n`)
+)
+
+// build the root build and settings files and make the modules rely on them. Plugins' versions,
+// group, version, repositories and BOMs are moved to the root build
+func assembleGradle(root string, data *model.AppData, kotlinDsl bool) error {
+	buildFile := "build.gradle"
+	settingsFile := "settings.gradle"
+	if kotlinDsl {
+		buildFile += ".kts"
+		settingsFile += ".kts"
+	}
+
+	builds := make([]string, 0, len(data.Modules))
+	for _, m := range data.Modules {
+		content, err := os.ReadFile(filepath.Join(root, m.Name, buildFile))
+		if err != nil {
+			return err
+		}
+		builds = append(builds, string(content))
+	}
+
+	// collect the shared configuration of all the modules and remove it from the modules
+	plugins := newUnion()
+	coordinates := newUnion()
+	repositories := newUnion()
+	extras := newUnion()
+	boms := newUnion()
+	for i, build := range builds {
+		for _, p := range gradlePluginVersion.FindAllStringSubmatch(build, -1) {
+			plugins.add(p[1], fmt.Sprintf("\n\t%s version %s apply false", p[1], p[2]))
+		}
+		build = gradlePluginVersion.ReplaceAllString(build, "\t$1")
+
+		for _, c := range gradleCoordinates.FindAllString(build, -1) {
+			coordinates.add(c, "\n\t"+strings.TrimSuffix(c, "\n"))
+		}
+		build = gradleCoordinates.ReplaceAllString(build, "")
+
+		var body string
+		body, build = removeBlock(build, "repositories")
+		for _, line := range blockLines(body) {
+			repositories.add(line, "\n\t\t"+line)
+		}
+
+		body, build = removeBlock(build, "ext")
+		for _, line := range blockLines(body) {
+			extras.add(line, "\n\t\t"+line)
+		}
+		for _, e := range gradleExtra.FindAllString(build, -1) {
+			extras.add(e, "\n\t"+strings.TrimSuffix(e, "\n"))
+		}
+		build = gradleExtra.ReplaceAllString(build, "")
+
+		body, build = removeBlock(build, "dependencyManagement")
+		imports, _ := removeBlock(strings.ReplaceAll(body, "\n\t", "\n"), "imports")
+		for _, line := range blockLines(imports) {
+			boms.add(line, "\n\t\t\t"+line)
+		}
+
+		builds[i] = regexp.MustCompile(`\n{3,}`).ReplaceAllString(build, "\n\n")
+	}
+
+	// write the root build
+	var b strings.Builder
+	b.WriteString("plugins {" + plugins.join() + "\n}\n\n")
+	b.WriteString("allprojects {" + coordinates.join())
+	if repositories.len() > 0 {
+		b.WriteString("\n\n\trepositories {" + repositories.join() + "\n\t}")
+	}
+	b.WriteString("\n}\n")
+	if extras.len() > 0 || boms.len() > 0 {
+		b.WriteString("\nsubprojects {")
+		if kotlinDsl {
+			b.WriteString("\n\tapply(plugin = \"io.spring.dependency-management\")")
+		} else {
+			b.WriteString("\n\tapply plugin: 'io.spring.dependency-management'")
+		}
+		if extras.len() > 0 {
+			if kotlinDsl {
+				b.WriteString("\n" + extras.join())
+			} else {
+				b.WriteString("\n\n\text {" + extras.join() + "\n\t}")
+			}
+		}
+		if boms.len() > 0 {
+			if kotlinDsl {
+				b.WriteString("\n\n\tconfigure<io.spring.gradle.dependencymanagement.dsl.DependencyManagementExtension> {")
+			} else {
+				b.WriteString("\n\n\tdependencyManagement {")
+			}
+			b.WriteString("\n\t\timports {" + boms.join() + "\n\t\t}\n\t}")
+		}
+		b.WriteString("\n}\n")
+	}
+	if err := os.WriteFile(filepath.Join(root, buildFile), []byte(b.String()), 0644); err != nil {
+		return err
+	}
+
+	// write the root settings, including the modules
+	names := make([]string, 0, len(data.Modules))
+	for _, m := range data.Modules {
+		names = append(names, m.Name)
+	}
+	var settings string
+	if kotlinDsl {
+		settings = fmt.Sprintf("rootProject.name = \"%s\"\n\ninclude(\"%s\")\n", data.Artifact, strings.Join(names, "\", \""))
+	} else {
+		settings = fmt.Sprintf("rootProject.name = '%s'\n\ninclude '%s'\n", data.Artifact, strings.Join(names, "', '"))
+	}
+	if err := os.WriteFile(filepath.Join(root, settingsFile), []byte(settings), 0644); err != nil {
+		return err
+	}
+
+	// write the modules' builds, their settings are replaced by the root's one
+	for i, m := range data.Modules {
+		if err := os.WriteFile(filepath.Join(root, m.Name, buildFile), []byte(builds[i]), 0644); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(filepath.Join(root, m.Name, settingsFile)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove the top level block with the given name, returns the block's body and the remaining text
+func removeBlock(text string, name string) (string, string) {
+	start := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(name) + `\s*\{`).FindStringIndex(text)
+	if start == nil {
+		return "", text
+	}
+	depth := 0
+	for i := start[1] - 1; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				end := i + 1
+				if end < len(text) && text[end] == '\n' {
+					end++
+				}
+				return text[start[1]:i], text[:start[0]] + text[end:]
+			}
+		}
+	}
+	return "", text
+}
+
+// return the trimmed, non empty lines of a block's body
+func blockLines(body string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
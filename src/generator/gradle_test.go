@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssembleGradle(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		kotlinDsl bool
+		// expected content of each file of the assembled project
+		want map[string][]string
+		// content moved out of the modules' builds
+		moved []string
+	}{
+		{"gradle", false, map[string][]string{
+			"build.gradle": {
+				"plugins {\n\tid 'org.springframework.boot' version '3.0.1' apply false\n\tid 'io.spring.dependency-management' version '1.1.0' apply false\n}",
+				"allprojects {\n\tgroup = 'com.example'\n\tversion = '0.0.1-SNAPSHOT'\n\n\trepositories {\n\t\tmavenCentral()\n\t\tmaven { url 'https://repo.spring.io/milestone' }\n\t}\n}",
+				"subprojects {\n\tapply plugin: 'io.spring.dependency-management'\n\n\text {\n\t\tset('springCloudVersion', \"2022.0.0\")\n\t}",
+				"\tdependencyManagement {\n\t\timports {\n\t\t\tmavenBom \"org.springframework.cloud:spring-cloud-dependencies:${springCloudVersion}\"\n\t\t}\n\t}",
+			},
+			"settings.gradle": {"rootProject.name = 'demo'\n\ninclude 'api', 'domain'\n"},
+			"api/build.gradle": {
+				"plugins {\n\tid 'java'\n\tid 'org.springframework.boot'\n\tid 'io.spring.dependency-management'\n}",
+				"\timplementation 'org.springframework.cloud:spring-cloud-starter-openfeign'",
+				"configurations {\n\tcompileOnly {",
+			},
+			"domain/build.gradle": {
+				"\truntimeOnly 'org.postgresql:postgresql'",
+				"tasks.named('test') {",
+			},
+		}, []string{"version '", "group =", "repositories", "ext {", "dependencyManagement", "\n\n\n"}},
+		{"gradle-kotlin", true, map[string][]string{
+			"build.gradle.kts": {
+				"\tkotlin(\"jvm\") version \"1.7.22\" apply false\n\tkotlin(\"plugin.spring\") version \"1.7.22\" apply false\n\tkotlin(\"plugin.jpa\") version \"1.7.22\" apply false\n}",
+				"allprojects {\n\tgroup = \"com.example\"\n\tversion = \"0.0.1-SNAPSHOT\"\n\n\trepositories {\n\t\tmavenCentral()\n\t}\n}",
+				"subprojects {\n\tapply(plugin = \"io.spring.dependency-management\")\n\n\textra[\"springCloudVersion\"] = \"2022.0.0\"",
+				"\tconfigure<io.spring.gradle.dependencymanagement.dsl.DependencyManagementExtension> {\n\t\timports {\n\t\t\tmavenBom(\"org.springframework.cloud:spring-cloud-dependencies:${property(\"springCloudVersion\")}\")",
+			},
+			"settings.gradle.kts": {"rootProject.name = \"demo\"\n\ninclude(\"api\", \"domain\")\n"},
+			"api/build.gradle.kts": {
+				"import org.jetbrains.kotlin.gradle.tasks.KotlinCompile\n\nplugins {\n\tid(\"org.springframework.boot\")\n\tid(\"io.spring.dependency-management\")\n\tkotlin(\"jvm\")\n\tkotlin(\"plugin.spring\")\n}",
+				"\timplementation(\"org.springframework.cloud:spring-cloud-starter-openfeign\")",
+				"tasks.withType<KotlinCompile> {\n\tkotlinOptions {",
+			},
+			"domain/build.gradle.kts": {
+				"\tkotlin(\"plugin.jpa\")\n}",
+				"\truntimeOnly(\"org.postgresql:postgresql\")",
+			},
+		}, []string{"version \"", "group =", "repositories", "extra[", "dependencyManagement", "\n\n\n"}},
+	}
+	for _, test := range tests {
+		data := modulesData()
+		root := copyModules(t, test.fixture, data)
+		if err := assembleGradle(root, data, test.kotlinDsl); err != nil {
+			t.Fatalf("%s: %v", test.fixture, err)
+		}
+		for file, want := range test.want {
+			content := readFile(t, root, file)
+			for _, w := range want {
+				if !strings.Contains(content, w) {
+					t.Errorf("%s: %s misses %q:\n%s", test.fixture, file, w, content)
+				}
+			}
+		}
+		for _, m := range data.Modules {
+			buildFile := "build.gradle"
+			if test.kotlinDsl {
+				buildFile += ".kts"
+			}
+			build := readFile(t, root, m.Name, buildFile)
+			for _, moved := range test.moved {
+				if strings.Contains(build, moved) {
+					t.Errorf("%s: the %s build still has %q:\n%s", test.fixture, m.Name, moved, build)
+				}
+			}
+			// the root's settings replace the module's one
+			for _, settings := range []string{"settings.gradle", "settings.gradle.kts"} {
+				if exists(filepath.Join(root, m.Name, settings)) {
+					t.Errorf("%s: the %s module still has its %s", test.fixture, m.Name, settings)
+				}
+			}
+		}
+	}
+}
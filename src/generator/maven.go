@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"tacher/src/model"
+)
+
+// poms generated by Spring initializer are indented with tabs, sections are found by their depth
+var (
+	pomPlugin         = regexp.MustCompile(`(?s)\n\t\t\t<plugin>.*?\n\t\t\t</plugin>`)
+	pomPluginIdentity = regexp.MustCompile(`\n\t\t\t\t<(groupId|artifactId)>[^<]*</(groupId|artifactId)>`)
+	pomProperty       = regexp.MustCompile(`\n\t\t<([^>\s]+)>.*?</[^>]+>`)
+	pomChild          = regexp.MustCompile(`(?s)\n\t\t(\t)?<(dependency|repository|pluginRepository)>.*?\n\t\t(\t)?</(dependency|repository|pluginRepository)>`)
+)
+
+// build the parent pom.xml and make the modules inherit from it. Properties, BOMs, plugins'
+// configuration and repositories are moved to the parent
+func assembleMaven(root string, data *model.AppData) error {
+	poms := make([]string, 0, len(data.Modules))
+	for _, m := range data.Modules {
+		content, err := os.ReadFile(filepath.Join(root, m.Name, "pom.xml"))
+		if err != nil {
+			return err
+		}
+		poms = append(poms, string(content))
+	}
+
+	// collect the shared configuration of all the modules
+	properties := newUnion()
+	boms := newUnion()
+	plugins := newUnion()
+	repositories := newUnion()
+	pluginRepositories := newUnion()
+	for _, pom := range poms {
+		for _, p := range pomProperty.FindAllStringSubmatch(pomSection(pom, "properties", 1), -1) {
+			properties.add(p[1], p[0])
+		}
+		for _, d := range pomChild.FindAllString(pomSection(pom, "dependencyManagement", 1), -1) {
+			boms.add(d, d)
+		}
+		// a plugin is declared once, with the configuration of the first module using it
+		for _, p := range pomPlugin.FindAllString(pomSection(pom, "build", 1), -1) {
+			plugins.add(strings.Join(pomPluginIdentity.FindAllString(p, -1), ""), p)
+		}
+		for _, r := range pomChild.FindAllString(pomSection(pom, "repositories", 1), -1) {
+			repositories.add(r, r)
+		}
+		for _, r := range pomChild.FindAllString(pomSection(pom, "pluginRepositories", 1), -1) {
+			pluginRepositories.add(r, r)
+		}
+	}
+
+	// the parent keeps the header and the Spring Boot parent of the first module
+	first := poms[0]
+	parentEnd := strings.Index(first, "\t</parent>")
+	if parentEnd < 0 {
+		return fmt.Errorf("no parent found in %s's pom.xml", data.Modules[0].Name)
+	}
+	version := pomValue(first, "version", 1)
+
+	var b strings.Builder
+	b.WriteString(first[:parentEnd+len("\t</parent>")])
+	fmt.Fprintf(&b, "\n\t<groupId>%s</groupId>", escape(data.Group))
+	fmt.Fprintf(&b, "\n\t<artifactId>%s</artifactId>", escape(data.Artifact))
+	fmt.Fprintf(&b, "\n\t<version>%s</version>", version)
+	b.WriteString("\n\t<packaging>pom</packaging>")
+	fmt.Fprintf(&b, "\n\t<name>%s</name>", escape(data.Name))
+	fmt.Fprintf(&b, "\n\t<description>%s</description>", escape(data.Description))
+	b.WriteString("\n\t<modules>")
+	for _, m := range data.Modules {
+		fmt.Fprintf(&b, "\n\t\t<module>%s</module>", m.Name)
+	}
+	b.WriteString("\n\t</modules>")
+	if properties.len() > 0 {
+		b.WriteString("\n\t<properties>" + properties.join() + "\n\t</properties>")
+	}
+	if boms.len() > 0 {
+		b.WriteString("\n\t<dependencyManagement>\n\t\t<dependencies>" + boms.join() + "\n\t\t</dependencies>\n\t</dependencyManagement>")
+	}
+	if plugins.len() > 0 {
+		b.WriteString("\n\n\t<build>\n\t\t<pluginManagement>\n\t\t\t<plugins>" + indent(plugins.join()) + "\n\t\t\t</plugins>\n\t\t</pluginManagement>\n\t</build>")
+	}
+	if repositories.len() > 0 {
+		b.WriteString("\n\t<repositories>" + repositories.join() + "\n\t</repositories>")
+	}
+	if pluginRepositories.len() > 0 {
+		b.WriteString("\n\t<pluginRepositories>" + pluginRepositories.join() + "\n\t</pluginRepositories>")
+	}
+	b.WriteString("\n\n</project>\n")
+	if err := os.WriteFile(filepath.Join(root, "pom.xml"), []byte(b.String()), 0644); err != nil {
+		return err
+	}
+
+	// make the modules inherit from the parent
+	parent := fmt.Sprintf("\t<parent>\n\t\t<groupId>%s</groupId>\n\t\t<artifactId>%s</artifactId>\n\t\t<version>%s</version>\n\t</parent>", escape(data.Group), escape(data.Artifact), version)
+	for i, m := range data.Modules {
+		pom := poms[i]
+		pom = replaceSection(pom, "parent", 1, parent)
+		pom = regexp.MustCompile(`\n\t<(groupId|version)>[^<]*</(groupId|version)>`).ReplaceAllString(pom, "")
+		for _, section := range []string{"properties", "dependencyManagement", "repositories", "pluginRepositories"} {
+			pom = replaceSection(pom, section, 1, "")
+		}
+		pom = pomPlugin.ReplaceAllStringFunc(pom, func(plugin string) string {
+			return "\n\t\t\t<plugin>" + strings.Join(pomPluginIdentity.FindAllString(plugin, -1), "") + "\n\t\t\t</plugin>"
+		})
+		pom = regexp.MustCompile(`\n{3,}`).ReplaceAllString(pom, "\n\n")
+		if err := os.WriteFile(filepath.Join(root, m.Name, "pom.xml"), []byte(pom), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// return the section with the given tag at the given depth, or an empty string
+func pomSection(pom string, tag string, depth int) string {
+	return sectionPattern(tag, depth).FindString(pom)
+}
+
+// replace the section with the given tag at the given depth
+func replaceSection(pom string, tag string, depth int, replacement string) string {
+	if replacement != "" {
+		replacement = "\n" + replacement
+	}
+	return sectionPattern(tag, depth).ReplaceAllLiteralString(pom, replacement)
+}
+
+// return the value of the element with the given tag at the given depth
+func pomValue(pom string, tag string, depth int) string {
+	tabs := strings.Repeat("\t", depth)
+	m := regexp.MustCompile(`\n` + tabs + `<` + tag + `>([^<]*)</` + tag + `>`).FindStringSubmatch(pom)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// pattern matching a section, including the new line before it
+func sectionPattern(tag string, depth int) *regexp.Regexp {
+	tabs := strings.Repeat("\t", depth)
+	return regexp.MustCompile(`(?s)\n` + tabs + `<` + tag + `>.*?\n` + tabs + `</` + tag + `>`)
+}
+
+// indent each line of the given text by a tab
+func indent(text string) string {
+	return strings.ReplaceAll(text, "\n", "\n\t")
+}
+
+// escape the text to be used as an XML value
+func escape(text string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// ordered set of strings identified by a key, the first value of each key wins
+type union struct {
+	keys   map[string]bool
+	values []string
+}
+
+func newUnion() *union {
+	return &union{keys: make(map[string]bool)}
+}
+
+func (u *union) add(key string, value string) {
+	if !u.keys[key] {
+		u.keys[key] = true
+		u.values = append(u.values, value)
+	}
+}
+
+func (u *union) len() int {
+	return len(u.values)
+}
+
+func (u *union) join() string {
+	return strings.Join(u.values, "")
+}
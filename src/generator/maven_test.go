@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssembleMaven(t *testing.T) {
+	data := modulesData()
+	root := copyModules(t, "maven", data)
+	if err := assembleMaven(root, data); err != nil {
+		t.Fatal(err)
+	}
+
+	// the poms are still valid XML
+	var parent struct {
+		Parent struct {
+			ArtifactId string `xml:"artifactId"`
+		} `xml:"parent"`
+		ArtifactId string   `xml:"artifactId"`
+		Packaging  string   `xml:"packaging"`
+		Modules    []string `xml:"modules>module"`
+		Plugins    []string `xml:"build>pluginManagement>plugins>plugin>artifactId"`
+	}
+	pom := readFile(t, root, "pom.xml")
+	if err := xml.Unmarshal([]byte(pom), &parent); err != nil {
+		t.Fatalf("invalid parent pom: %v\n%s", err, pom)
+	}
+	if parent.Parent.ArtifactId != "spring-boot-starter-parent" || parent.ArtifactId != "demo" || parent.Packaging != "pom" {
+		t.Errorf("parent = %+v, want the demo pom inheriting from Spring Boot", parent)
+	}
+	if want := []string{"api", "domain"}; !reflect.DeepEqual(parent.Modules, want) {
+		t.Errorf("modules = %q, want %q", parent.Modules, want)
+	}
+	// the plugin is declared once, with the configuration of the first module
+	if want := []string{"spring-boot-maven-plugin"}; !reflect.DeepEqual(parent.Plugins, want) {
+		t.Errorf("managed plugins = %q, want %q", parent.Plugins, want)
+	}
+	for _, want := range []string{
+		"\t<properties>\n\t\t<java.version>17</java.version>\n\t\t<spring-cloud.version>2022.0.0</spring-cloud.version>\n\t</properties>",
+		"\t\t\t\t<artifactId>spring-cloud-dependencies</artifactId>\n\t\t\t\t<version>${spring-cloud.version}</version>",
+		"\t\t\t\t\t<configuration>\n\t\t\t\t\t\t<excludes>",
+		"\t\t\t<url>https://repo.spring.io/milestone</url>",
+	} {
+		if !strings.Contains(pom, want) {
+			t.Errorf("the parent pom misses %q:\n%s", want, pom)
+		}
+	}
+
+	tests := []struct {
+		module string
+		want   []string
+	}{
+		{"api", []string{"<artifactId>spring-boot-starter-web</artifactId>", "<artifactId>spring-cloud-starter-openfeign</artifactId>"}},
+		{"domain", []string{"<artifactId>spring-boot-starter-data-jpa</artifactId>", "<artifactId>postgresql</artifactId>"}},
+	}
+	for _, test := range tests {
+		pom := readFile(t, root, test.module, "pom.xml")
+		var module struct {
+			GroupId    string `xml:"groupId"`
+			ArtifactId string `xml:"artifactId"`
+		}
+		if err := xml.Unmarshal([]byte(pom), &module); err != nil {
+			t.Fatalf("invalid %s pom: %v\n%s", test.module, err, pom)
+		}
+		if module.GroupId != "" || module.ArtifactId != test.module {
+			t.Errorf("%s = %+v, want the module's artifact inheriting the group", test.module, module)
+		}
+		want := append(test.want,
+			"\t<parent>\n\t\t<groupId>com.example</groupId>\n\t\t<artifactId>demo</artifactId>\n\t\t<version>0.0.1-SNAPSHOT</version>\n\t</parent>",
+			"\t\t\t<plugin>\n\t\t\t\t<groupId>org.springframework.boot</groupId>\n\t\t\t\t<artifactId>spring-boot-maven-plugin</artifactId>\n\t\t\t</plugin>")
+		for _, w := range want {
+			if !strings.Contains(pom, w) {
+				t.Errorf("the %s pom misses %q:\n%s", test.module, w, pom)
+			}
+		}
+		// the shared configuration moved to the parent
+		for _, moved := range []string{"<properties>", "<dependencyManagement>", "<repositories>", "<configuration>", "spring-boot-starter-parent", "\n\n\n"} {
+			if strings.Contains(pom, moved) {
+				t.Errorf("the %s pom still has %q:\n%s", test.module, moved, pom)
+			}
+		}
+	}
+}
+
+func TestAssembleMavenWithoutParent(t *testing.T) {
+	data := modulesData()
+	root := copyModules(t, "maven", data)
+	pom := filepath.Join(root, "api", "pom.xml")
+	writeFile(t, pom, replaceSection(readFile(t, pom), "parent", 1, ""))
+	if err := assembleMaven(root, data); err == nil {
+		t.Error("assembleMaven() succeeded without a Spring Boot parent, want an error")
+	}
+}
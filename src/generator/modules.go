@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"tacher/src/errs"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// files shared by all the modules, they are kept only once in the root of the project
+var sharedFiles = []string{".gitignore", "HELP.md", "mvnw", "mvnw.cmd", ".mvn", "gradlew", "gradlew.bat", "gradle"}
+
+// module of a multi-module project, dependencies are referenced by ID
+type ModuleDefinition struct {
+	Name         string   `yaml:"name"`
+	Dependencies []string `yaml:"dependencies"`
+}
+
+// preset file describing the modules of a multi-module project
+type modulesPreset struct {
+	Modules []ModuleDefinition `yaml:"modules"`
+}
+
+// load the modules defined in the given preset file. Dependencies are referenced by ID and
+// must be offered by Spring initializer
+func LoadModules(file string, state *model.AppState) ([]model.Module, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var preset modulesPreset
+	if err := yaml.Unmarshal(content, &preset); err != nil {
+		return nil, errs.Input("can't parse %s: %w", file, err)
+	}
+
+	return ToModules(preset.Modules, state)
+}
+
+// map the modules' definitions on the dependencies offered by Spring initializer
+func ToModules(definitions []ModuleDefinition, state *model.AppState) ([]model.Module, error) {
+	modules := make([]model.Module, 0, len(definitions))
+	for _, m := range definitions {
+		if m.Name == "" {
+			return nil, errs.Input("a module has no name")
+		}
+		deps, err := ResolveDependencies(m.Dependencies, state)
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", m.Name, err)
+		}
+		modules = append(modules, model.Module{Name: m.Name, Dependencies: deps})
+	}
+	return modules, nil
+}
+
+// generates each module with Spring initializer and assembles them under a parent build. Each
+// module has its own package, so that their application classes don't clash
+func generateModules(data *model.AppData) error {
+	root := filepath.Join(data.Path, data.Artifact)
+	for _, m := range data.Modules {
+		module := *data
+		module.Artifact = m.Name
+		module.Name = m.Name
+		module.Pkg = modulePackage(data.Pkg, m.Name)
+		module.Dependencies = m.Dependencies
+		logging.Debug("generating a module", "module", m.Name, "dependencies", len(m.Dependencies))
+		if err := generateProject(&module, root); err != nil {
+			return fmt.Errorf("can't generate module %s: %w", m.Name, err)
+		}
+	}
+
+	// the build tool is recognized from the first module's build file
+	first := filepath.Join(root, data.Modules[0].Name)
+	var err error
+	switch {
+	case exists(filepath.Join(first, "pom.xml")):
+		err = assembleMaven(root, data)
+	case exists(filepath.Join(first, "build.gradle")):
+		err = assembleGradle(root, data, false)
+	case exists(filepath.Join(first, "build.gradle.kts")):
+		err = assembleGradle(root, data, true)
+	default:
+		err = fmt.Errorf("no build file found in module %s", data.Modules[0].Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	return deduplicateSharedFiles(root, data.Modules)
+}
+
+// package of a module, the project's package followed by the module's name without the
+// characters that Java doesn't allow
+func modulePackage(pkg string, module string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(module) {
+		if r == '_' || unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return pkg
+	}
+	if pkg == "" {
+		return b.String()
+	}
+	return pkg + "." + b.String()
+}
+
+// keep a single copy of the shared files in the root of the project, the first module having
+// one gives it unless the root already has it. The modules' copies are removed
+func deduplicateSharedFiles(root string, modules []model.Module) error {
+	for _, name := range sharedFiles {
+		target := filepath.Join(root, name)
+		for _, m := range modules {
+			path := filepath.Join(root, m.Name, name)
+			if !exists(path) {
+				continue
+			}
+			if !exists(target) {
+				if err := os.Rename(path, target); err != nil {
+					return err
+				}
+			} else if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// check if the given file exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"tacher/src/model"
+	"testing"
+)
+
+// project with the api and domain modules, as in the fixtures
+func modulesData() *model.AppData {
+	return &model.AppData{
+		Group:       "com.example",
+		Artifact:    "demo",
+		Name:        "demo",
+		Description: "Demo project",
+		Pkg:         "com.example.demo",
+		Modules:     []model.Module{{Name: "api"}, {Name: "domain"}},
+	}
+}
+
+// copy the modules generated by Spring initializer from the fixture to a temporary root
+func copyModules(t *testing.T, fixture string, data *model.AppData) string {
+	root := t.TempDir()
+	for _, m := range data.Modules {
+		dir := filepath.Join("testdata", fixture, m.Name)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(filepath.Join(root, m.Name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, filepath.Join(root, m.Name, e.Name()), string(content))
+		}
+	}
+	return root
+}
+
+// read a file of the assembled project
+func readFile(t *testing.T, path ...string) string {
+	content, err := os.ReadFile(filepath.Join(path...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestModulePackage(t *testing.T) {
+	tests := []struct {
+		pkg    string
+		module string
+		want   string
+	}{
+		{"com.example.demo", "api", "com.example.demo.api"},
+		{"com.example.demo", "Billing-Service", "com.example.demo.billingservice"},
+		{"com.example.demo", "v2.api", "com.example.demo.v2api"},
+		{"com.example.demo", "2fa", "com.example.demo.fa"},
+		{"com.example.demo", "--", "com.example.demo"},
+		{"", "api", "api"},
+	}
+	for _, test := range tests {
+		if got := modulePackage(test.pkg, test.module); got != test.want {
+			t.Errorf("modulePackage(%q, %q) = %q, want %q", test.pkg, test.module, got, test.want)
+		}
+	}
+}
+
+func TestDeduplicateSharedFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		// the root already has the .gitignore of an overlay
+		".gitignore":               "root",
+		"api/.gitignore":           "api",
+		"api/HELP.md":              "api",
+		"api/.mvn/wrapper/jar":     "api",
+		"domain/.gitignore":        "domain",
+		"domain/.mvn/wrapper/jar":  "domain",
+		"domain/mvnw":              "domain",
+		"domain/src/Application.x": "domain",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, content)
+	}
+
+	if err := deduplicateSharedFiles(root, modulesData().Modules); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		".gitignore":               "root",
+		"HELP.md":                  "api",
+		".mvn/wrapper/jar":         "api",
+		"mvnw":                     "domain",
+		"domain/src/Application.x": "domain",
+	}
+	for name, content := range want {
+		if got := readFile(t, root, filepath.FromSlash(name)); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+	for _, name := range []string{"api/.gitignore", "api/HELP.md", "api/.mvn", "domain/.gitignore", "domain/.mvn", "domain/mvnw"} {
+		if exists(filepath.Join(root, filepath.FromSlash(name))) {
+			t.Errorf("%s wasn't removed", name)
+		}
+	}
+}
+
+// write a file of the project
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"tacher/src/utils"
+)
+
+const HISTORY_FILE = "history.json"
+
+// number of recently used dependencies that are remembered
+const RECENT_SIZE = 10
+
+// dependencies chosen in the previous runs, referenced by ID
+type History struct {
+	// dependencies pinned by the user
+	Favorites []string `json:"favorites"`
+	// dependencies of the last generated projects, the most recent first
+	Recent []string `json:"recent"`
+}
+
+// path of the history file in tacher's configuration directory
+func path() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, HISTORY_FILE), nil
+}
+
+// load the history, it's empty if it was never saved
+func Load() (*History, error) {
+	h := new(History)
+	file, err := path()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// save the history in tacher's configuration directory
+func (h *History) Save() error {
+	file, err := path()
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0644)
+}
+
+// record the dependencies of a generated project as the most recently used ones
+func (h *History) Record(ids []string) {
+	recent := append([]string(nil), ids...)
+	for _, id := range h.Recent {
+		if !utils.Contains(recent, id) {
+			recent = append(recent, id)
+		}
+	}
+	if len(recent) > RECENT_SIZE {
+		recent = recent[:RECENT_SIZE]
+	}
+	h.Recent = recent
+}
+
+// pin or unpin a dependency, returns true if the dependency is now a favorite
+func (h *History) ToggleFavorite(id string) bool {
+	if idx, found := utils.Find(h.Favorites, func(f string) bool { return f == id }); found {
+		h.Favorites = utils.RemoveIndex(h.Favorites, idx)
+		return false
+	}
+	h.Favorites = append(h.Favorites, id)
+	return true
+}
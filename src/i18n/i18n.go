@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// language of the messages missing from the other catalogs
+const DEFAULT_LANGUAGE = "en"
+
+// catalogs of the messages, one YAML file per language mapping the messages' keys to their text
+//
+//go:embed catalogs/*.yaml
+var files embed.FS
+
+// messages of each language, by key
+var catalogs = make(map[string]map[string]string)
+
+// language of the messages
+var language = DEFAULT_LANGUAGE
+
+func init() {
+	entries, err := files.ReadDir("catalogs")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		content, err := files.ReadFile(path.Join("catalogs", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := make(map[string]string)
+		if err := yaml.Unmarshal(content, &messages); err != nil {
+			panic(fmt.Errorf("invalid catalog %s: %w", e.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(e.Name(), path.Ext(e.Name()))] = messages
+	}
+}
+
+// languages having a catalog, sorted
+func Languages() []string {
+	ret := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		ret = append(ret, lang)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// use the language for the messages, the one of the environment if it's empty. Languages without
+// a catalog fall back to English
+func SetLanguage(lang string) {
+	if lang == "" {
+		lang = Detect()
+	}
+	lang = Normalize(lang)
+	if _, found := catalogs[lang]; !found {
+		lang = DEFAULT_LANGUAGE
+	}
+	language = lang
+}
+
+// language of the messages
+func Language() string {
+	return language
+}
+
+// language of the environment, from the locale variables in POSIX order of precedence
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return Normalize(v)
+		}
+	}
+	return DEFAULT_LANGUAGE
+}
+
+// language code of a locale, fr_FR.UTF-8 is fr. The C and POSIX locales are English
+func Normalize(locale string) string {
+	lang := strings.ToLower(locale)
+	if idx := strings.IndexAny(lang, "_-.@"); idx >= 0 {
+		lang = lang[:idx]
+	}
+	if lang == "" || lang == "c" || lang == "posix" {
+		return DEFAULT_LANGUAGE
+	}
+	return lang
+}
+
+// text of the message in the current language, formatted with the arguments like fmt.Sprintf.
+// Messages missing from the language's catalog are in English
+func T(key string, args ...any) string {
+	msg, found := catalogs[language][key]
+	if !found {
+		if msg, found = catalogs[DEFAULT_LANGUAGE][key]; !found {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,140 @@
+package jdk
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"tacher/src/logging"
+)
+
+// file of a JDK's home describing its version
+const RELEASE_FILE = "release"
+
+// JDK installed on the machine
+type JDK struct {
+	// major version, 1.8 is 8
+	Version int
+	Home    string
+}
+
+// find the JDKs installed in the usual places: JAVA_HOME, the PATH, /usr/lib/jvm, SDKMAN and asdf.
+// Returns them from the most recent version, each home only once
+func Detect() []JDK {
+	candidates := make([]string, 0)
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		candidates = append(candidates, home)
+	}
+	if java, err := exec.LookPath(executable()); err == nil {
+		// the home is the parent of the bin directory, once the links are followed
+		if resolved, err := filepath.EvalSymlinks(java); err == nil {
+			candidates = append(candidates, filepath.Dir(filepath.Dir(resolved)))
+		}
+	}
+	candidates = append(candidates, children("/usr/lib/jvm")...)
+	if home, err := os.UserHomeDir(); err == nil {
+		sdkman := envOrElse("SDKMAN_DIR", filepath.Join(home, ".sdkman"))
+		candidates = append(candidates, children(filepath.Join(sdkman, "candidates", "java"))...)
+		asdf := envOrElse("ASDF_DATA_DIR", filepath.Join(home, ".asdf"))
+		candidates = append(candidates, children(filepath.Join(asdf, "installs", "java"))...)
+	}
+
+	jdks := make([]JDK, 0)
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		home, err := filepath.EvalSymlinks(c)
+		if err != nil || seen[home] {
+			continue
+		}
+		seen[home] = true
+		if version, found := readVersion(home); found {
+			logging.Debug("JDK found", "home", home, "version", version)
+			jdks = append(jdks, JDK{Version: version, Home: home})
+		}
+	}
+	sort.SliceStable(jdks, func(i, j int) bool { return jdks[i].Version > jdks[j].Version })
+	return jdks
+}
+
+// name of the java executable
+func executable() string {
+	if runtime.GOOS == "windows" {
+		return "java.exe"
+	}
+	return "java"
+}
+
+func envOrElse(name string, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// paths of the directory's entries, none if it can't be read
+func children(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	return paths
+}
+
+// major version of the JDK in the home, from its release file. The java of the old JDKs is in a
+// jre directory whose parent has the release file
+func readVersion(home string) (int, bool) {
+	for _, dir := range []string{home, filepath.Dir(home)} {
+		if value, found := releaseValue(filepath.Join(dir, RELEASE_FILE), "JAVA_VERSION"); found {
+			version, err := MajorVersion(value)
+			return version, err == nil
+		}
+	}
+	return 0, false
+}
+
+// value of a key of the release file, without its quotes
+func releaseValue(file string, key string) (string, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, key+"=") {
+			return strings.Trim(strings.TrimPrefix(line, key+"="), `"`), true
+		}
+	}
+	return "", false
+}
+
+// major number of a Java version, 1.8.0_292 is 8 and 17.0.2 is 17
+func MajorVersion(version string) (int, error) {
+	version = strings.TrimPrefix(version, "1.")
+	if idx := strings.IndexAny(version, "._+-"); idx >= 0 {
+		version = version[:idx]
+	}
+	return strconv.Atoi(version)
+}
+
+// check if a JDK of the Java version is installed
+func Installed(jdks []JDK, id string) bool {
+	version, err := MajorVersion(id)
+	if err != nil {
+		return false
+	}
+	for _, j := range jdks {
+		if j.Version == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"tacher/src/errs"
+	"time"
+)
+
+// levels of the log, from the most verbose
+const LEVEL_DEBUG = "debug"
+const LEVEL_INFO = "info"
+const LEVEL_WARN = "warn"
+const LEVEL_ERROR = "error"
+
+var levels = []string{LEVEL_DEBUG, LEVEL_INFO, LEVEL_WARN, LEVEL_ERROR}
+
+// placeholder of the secrets removed from the log
+const REDACTED = "REDACTED"
+
+// query parameters whose value is never logged
+var secretParams = []string{"token", "access_token", "password", "secret", "key", "api_key", "apikey", "auth"}
+
+// destination of the log, nothing is logged until a file is set up
+var output io.Writer = io.Discard
+
+// index of the lowest level logged
+var minLevel = 1
+
+var mutex sync.Mutex
+
+// names of the levels, from the most verbose
+func Levels() []string {
+	return append([]string(nil), levels...)
+}
+
+// append the log to the file from the given level on, an empty file disables the log
+func Setup(file string, level string) error {
+	idx := indexOf(strings.ToLower(level))
+	if idx < 0 {
+		return errs.Input("invalid log level %q, one of %s", level, strings.Join(levels, ", "))
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	minLevel = idx
+	if file == "" {
+		output = io.Discard
+		return nil
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("can't open the log file: %w", err)
+	}
+	output = f
+	return nil
+}
+
+func indexOf(level string) int {
+	for i, l := range levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// check if the level is logged, to avoid building expensive values for nothing
+func Enabled(level string) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return output != io.Discard && indexOf(level) >= minLevel
+}
+
+func Debug(msg string, keyValues ...any) {
+	log(LEVEL_DEBUG, msg, keyValues)
+}
+
+func Info(msg string, keyValues ...any) {
+	log(LEVEL_INFO, msg, keyValues)
+}
+
+func Warn(msg string, keyValues ...any) {
+	log(LEVEL_WARN, msg, keyValues)
+}
+
+func Error(msg string, keyValues ...any) {
+	log(LEVEL_ERROR, msg, keyValues)
+}
+
+// write a line in the logfmt format, the message is followed by the pairs of keys and values
+func log(level string, msg string, keyValues []any) {
+	if !Enabled(level) {
+		return
+	}
+	var line strings.Builder
+	line.WriteString("time=" + time.Now().Format(time.RFC3339Nano))
+	line.WriteString(" level=" + level)
+	line.WriteString(" msg=" + quote(msg))
+	for i := 0; i < len(keyValues); i += 2 {
+		var v any = "MISSING"
+		if i+1 < len(keyValues) {
+			v = keyValues[i+1]
+		}
+		line.WriteString(fmt.Sprintf(" %v=%s", keyValues[i], quote(format(v))))
+	}
+	line.WriteString("\n")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	io.WriteString(output, line.String())
+}
+
+// format the value, durations in milliseconds to be easy to compare
+func format(v any) string {
+	switch value := v.(type) {
+	case time.Duration:
+		return strconv.FormatInt(value.Milliseconds(), 10) + "ms"
+	case error:
+		return value.Error()
+	case *url.URL:
+		return RedactURL(value)
+	}
+	return fmt.Sprint(v)
+}
+
+// quote the value if it can't be read back without quotes
+func quote(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\n\\") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// the URL without the password of its user and without the values of its secret parameters
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		if _, hasPassword := redacted.User.Password(); hasPassword {
+			redacted.User = url.UserPassword(redacted.User.Username(), REDACTED)
+		}
+	}
+	query := redacted.Query()
+	changed := false
+	for param := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(param, secret) {
+				query.Set(param, REDACTED)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+// the URL in the text, redacted. Texts that aren't URLs are returned as they are
+func Redact(text string) string {
+	u, err := url.Parse(text)
+	if err != nil {
+		return text
+	}
+	return RedactURL(u)
+}
@@ -1,9 +1,31 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
+	"tacher/src/batch"
+	"tacher/src/client"
+	"tacher/src/completion"
+	"tacher/src/config"
+	"tacher/src/diff"
+	"tacher/src/errs"
+	"tacher/src/generator"
+	"tacher/src/i18n"
+	"tacher/src/jdk"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"tacher/src/policy"
+	"tacher/src/result"
 	"tacher/src/ui"
+	"tacher/src/utils"
+	"text/tabwriter"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -13,18 +35,71 @@ func main() {
 		Name:        "tacher",
 		Version:     "v0.1",
 		HideVersion: false,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "log-file",
+				Usage: "Append the log to the `FILE`",
+			},
+			&cli.StringFlag{
+				Name:  "log-level",
+				Usage: "Lowest `LEVEL` logged: " + strings.Join(logging.Levels(), ", "),
+			},
+		},
 		Commands: []*cli.Command{
 			{
 				Name:  "init",
 				Usage: "init a Spring Boot project",
 				Action: func(ctx *cli.Context) error {
-					err := ui.RunUI(
-						ctx.String("group"),
-						ctx.String("artifact"),
-						ctx.String("name"),
-						ctx.String("description"),
-						ctx.String("package"),
-					)
+					cfg, err := loadConfig(ctx, initFlags)
+					if err != nil {
+						return fmt.Errorf("An error occured while loading the configuration: %w", err)
+					}
+					rules, err := policy.Load(utils.ExpandPath(cfg.Get(config.POLICY)))
+					if err != nil {
+						return err
+					}
+					servers, merge, err := serverOptions(cfg)
+					if err != nil {
+						return err
+					}
+					opts := ui.Options{
+						Defaults:   cfg.AppData(false),
+						Like:       ctx.String("like"),
+						Modules:    cfg.Get(config.MODULES),
+						Catalogs:   ctx.StringSlice("catalog"),
+						Opener:     cfg.Get(config.UI_OPENER),
+						Keys:       cfg.Prefixed(config.UI_KEYS),
+						Theme:      cfg.Get(config.UI_THEME),
+						Policy:     rules,
+						Generation: generator.Options{Overlays: ctx.StringSlice("overlay")},
+						Result:     result.New(),
+						JDKs:       jdk.Detect(),
+						Open:       ctx.Bool("open"),
+						Editor:     cfg.Get(config.UI_EDITOR),
+						PrintCd:    ctx.Bool("print-cd"),
+						CdFd:       ctx.Int("cd-fd"),
+						Servers:    servers,
+						Merge:      merge,
+					}
+					params := cfg.AppData(true)
+					// the standard output is kept for the result
+					out := os.Stdout
+					if ctx.String("result-json") == "-" {
+						out = os.Stderr
+					}
+					if ctx.Bool("headless") {
+						err = ui.RunHeadless(params, opts, out)
+					} else if ctx.Bool("prompt") {
+						err = ui.RunPrompt(params, opts, os.Stdin, out)
+					} else {
+						err = ui.RunUI(params, opts, out)
+					}
+					if file := ctx.String("result-json"); file != "" {
+						opts.Result.Finish(err)
+						if err := opts.Result.Write(file); err != nil {
+							return fmt.Errorf("An error occured while writing the result: %w", err)
+						}
+					}
 					if err != nil {
 						return fmt.Errorf("An error occured while setting up the application: %w", err)
 					}
@@ -56,11 +131,573 @@ func main() {
 						Usage:    "Package name",
 						Required: false,
 					},
+					&cli.StringFlag{
+						Name:  "type",
+						Usage: "Build tool, like maven-project or gradle-project",
+					},
+					&cli.StringFlag{
+						Name:  "language",
+						Usage: "Language, like java or kotlin",
+					},
+					&cli.StringFlag{
+						Name:  "java",
+						Usage: "Java `VERSION`",
+					},
+					&cli.StringFlag{
+						Name:  "boot",
+						Usage: "Spring Boot `VERSION`",
+					},
+					&cli.StringFlag{
+						Name:  "packaging",
+						Usage: "Packaging, jar or war",
+					},
+					&cli.StringFlag{
+						Name:  "dependencies",
+						Usage: "Comma separated `IDS` of the selected dependencies",
+					},
+					&cli.StringFlag{
+						Name:  "path",
+						Usage: "`DIR` where the project is created",
+					},
+					&cli.StringFlag{
+						Name:  "server",
+						Usage: "`URL` of Spring Initializr or name of one of the servers",
+					},
+					&cli.StringFlag{
+						Name:  "merge",
+						Usage: "Comma separated `NAMES` of the servers whose dependencies are merged with the ones of Spring Initializr",
+					},
+					&cli.StringFlag{
+						Name:     "like",
+						Usage:    "Pre-fill the wizard from the pom.xml or build.gradle of an existing project `DIR`",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "modules",
+						Usage:    "Generate a multi-module project with the modules defined in the preset `FILE`",
+						Required: false,
+					},
+					&cli.StringSliceFlag{
+						Name:  "overlay",
+						Usage: "Render the files of the overlay `DIR` in the generated project, can be repeated",
+					},
+					&cli.StringSliceFlag{
+						Name:  "catalog",
+						Usage: "Merge the dependencies of the catalog `FILE` with Spring Initializr's ones, can be repeated",
+					},
+					&cli.StringFlag{
+						Name:  "opener",
+						Usage: "`COMMAND` that opens the dependencies' documentation, the system's opener by default",
+					},
+					&cli.BoolFlag{
+						Name:  "headless",
+						Usage: "Generate the project from the flags and the configuration without asking anything",
+					},
+					&cli.StringFlag{
+						Name:  "policy",
+						Usage: "Policy `FILE` that the project must follow",
+					},
+					&cli.BoolFlag{
+						Name:  "prompt",
+						Usage: "Ask the questions line by line instead of showing the full-screen UI, the answers can be piped",
+					},
+					&cli.StringFlag{
+						Name:  "theme",
+						Usage: "`NAME` of the UI's theme: " + strings.Join(ui.ThemeNames(), ", "),
+					},
+					&cli.BoolFlag{
+						Name:  "open",
+						Usage: "Open the generated project with the editor",
+					},
+					&cli.StringFlag{
+						Name:  "editor",
+						Usage: "`COMMAND` of the editor opening the project, the first installed of $VISUAL, $EDITOR, code and idea by default",
+					},
+					&cli.BoolFlag{
+						Name:  "print-cd",
+						Usage: "Print a cd line to the generated project once it's created",
+					},
+					&cli.IntFlag{
+						Name:  "cd-fd",
+						Usage: "Write the generated project's directory to the file descriptor `FD`, for the shell wrappers",
+					},
+					&cli.StringFlag{
+						Name:  "result-json",
+						Usage: "Write what happened during the run as JSON in the `FILE`, - for the standard output",
+					},
+				},
+			},
+			{
+				Name:      "batch",
+				Usage:     "generate the Spring Boot projects listed in a manifest file",
+				ArgsUsage: "<manifest>",
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 1 {
+						return errs.Input("the manifest file is required")
+					}
+					cfg, err := loadConfig(ctx, batchFlags)
+					if err != nil {
+						return fmt.Errorf("An error occured while loading the configuration: %w", err)
+					}
+					rules, err := policy.Load(utils.ExpandPath(cfg.Get(config.POLICY)))
+					if err != nil {
+						return err
+					}
+					opts := batch.Options{
+						Workers:         ctx.Int("workers"),
+						RateLimit:       ctx.Float64("rate"),
+						ContinueOnError: ctx.Bool("continue-on-error"),
+						Catalogs:        ctx.StringSlice("catalog"),
+						Generation:      generator.Options{Overlays: ctx.StringSlice("overlay")},
+						Policy:          rules,
+					}
+					return batch.Run(ctx.Args().First(), opts, os.Stdout)
+				},
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "workers",
+						Usage: "Number of projects generated concurrently",
+						Value: 4,
+					},
+					&cli.StringFlag{
+						Name:  "server",
+						Usage: "`URL` of Spring Initializr",
+					},
+					&cli.StringFlag{
+						Name:  "policy",
+						Usage: "Policy `FILE` that the projects must follow",
+					},
+					&cli.Float64Flag{
+						Name:  "rate",
+						Usage: "Maximum number of requests per second sent to each host, 0 means no limit",
+						Value: 2,
+					},
+					&cli.BoolFlag{
+						Name:  "continue-on-error",
+						Usage: "Keep generating the remaining projects after a failure",
+					},
+					&cli.StringSliceFlag{
+						Name:  "overlay",
+						Usage: "Render the files of the overlay `DIR` in the generated project, can be repeated",
+					},
+					&cli.StringSliceFlag{
+						Name:  "catalog",
+						Usage: "Merge the dependencies of the catalog `FILE` with Spring Initializr's ones, can be repeated",
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "inspect and change the configuration",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "list the settings that are set",
+						Action: func(ctx *cli.Context) error {
+							cfg, err := loadConfig(ctx, nil)
+							if err != nil {
+								return err
+							}
+							for _, key := range cfg.Keys() {
+								printSetting(ctx, cfg, key, key+"="+cfg.Get(key))
+							}
+							return nil
+						},
+						Flags: []cli.Flag{showOriginFlag},
+					},
+					{
+						Name:      "get",
+						Usage:     "print the value of a setting",
+						ArgsUsage: "<key>",
+						Action: func(ctx *cli.Context) error {
+							if ctx.NArg() != 1 {
+								return errs.Input("the setting's key is required")
+							}
+							key := ctx.Args().First()
+							if _, found := config.Find(key); !found {
+								return errs.Input("unknown setting %s", key)
+							}
+							cfg, err := loadConfig(ctx, nil)
+							if err != nil {
+								return err
+							}
+							printSetting(ctx, cfg, key, cfg.Get(key))
+							return nil
+						},
+						Flags: []cli.Flag{showOriginFlag},
+					},
+					{
+						Name:      "set",
+						Usage:     "write a setting in the user's configuration file, or in the project's one",
+						ArgsUsage: "<key> <value>",
+						Action: func(ctx *cli.Context) error {
+							if ctx.NArg() != 2 {
+								return errs.Input("the setting's key and value are required")
+							}
+							file := config.PROJECT_CONFIG_FILE
+							if !ctx.Bool("project") {
+								var err error
+								if file, err = config.UserFile(); err != nil {
+									return err
+								}
+							}
+							return config.Write(file, ctx.Args().Get(0), ctx.Args().Get(1))
+						},
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "project",
+								Usage: "Write the setting in the " + config.PROJECT_CONFIG_FILE + " of the current directory",
+							},
+						},
+					},
+					{
+						Name:  "keys",
+						Usage: "list the available settings",
+						Action: func(ctx *cli.Context) error {
+							w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+							for _, s := range config.Settings {
+								fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, config.EnvName(s.Key), s.Description)
+							}
+							fmt.Fprintf(w, "%s<action>\t%s<ACTION>\t%s\n", config.UI_KEYS, config.EnvName(config.UI_KEYS), "key bound to an action of the UI")
+							fmt.Fprintf(w, "%s<name>\t%s<NAME>\t%s\n", config.SERVERS, config.EnvName(config.SERVERS), "URL of a named Spring Initializr")
+							return w.Flush()
+						},
+					},
+				},
+			},
+			{
+				Name:  "metadata",
+				Usage: "carry Spring Initializr's metadata onto the machines without network",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "export",
+						Usage:     "write the server's metadata in a file, from the cache while it's fresh",
+						ArgsUsage: "<file>",
+						Action: func(ctx *cli.Context) error {
+							if ctx.NArg() != 1 {
+								return errs.Input("the file is required")
+							}
+							if _, err := loadConfig(ctx, metadataFlags); err != nil {
+								return err
+							}
+							snapshot, err := client.TakeSnapshot()
+							if err != nil {
+								return fmt.Errorf("can't get the metadata: %w", err)
+							}
+							if err := snapshot.Write(ctx.Args().First()); err != nil {
+								return err
+							}
+							fmt.Printf("Metadata of %s exported to %s\n", logging.Redact(snapshot.Server), ctx.Args().First())
+							return nil
+						},
+						Flags: []cli.Flag{serverFlag},
+					},
+					{
+						Name:      "import",
+						Usage:     "put the metadata of an exported file in the cache of the server",
+						ArgsUsage: "<file>",
+						Action: func(ctx *cli.Context) error {
+							if ctx.NArg() != 1 {
+								return errs.Input("the file is required")
+							}
+							if _, err := loadConfig(ctx, metadataFlags); err != nil {
+								return err
+							}
+							snapshot, err := client.ReadSnapshot(ctx.Args().First())
+							if err != nil {
+								return err
+							}
+							if err := snapshot.Import(); err != nil {
+								return err
+							}
+							fmt.Printf("Metadata of %s taken on %s imported for %s\n", logging.Redact(snapshot.Server),
+								snapshot.Time.Format(time.RFC3339), logging.Redact(client.ServerURL()))
+							return nil
+						},
+						Flags: []cli.Flag{serverFlag},
+					},
+					{
+						Name:  "diff",
+						Usage: "show what changed in the server's metadata since the cached copy it replaced",
+						Action: func(ctx *cli.Context) error {
+							if _, err := loadConfig(ctx, metadataFlags); err != nil {
+								return err
+							}
+							current := new(model.AppState)
+							if err := client.RefreshOptions(current); err != nil {
+								return fmt.Errorf("can't get the metadata: %w", err)
+							}
+							previous := new(model.AppState)
+							since, err := client.GetPreviousOptions(previous)
+							if errors.Is(err, fs.ErrNotExist) {
+								// there's nothing to compare with until the metadata changes
+								previous = current
+							} else if err != nil {
+								return fmt.Errorf("can't read the previous metadata: %w", err)
+							}
+							return printChanges(ctx, diff.Compare(previous, current), since)
+						},
+						Flags: []cli.Flag{
+							serverFlag,
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "Print the changes as JSON",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:      "completion",
+				Usage:     "print the script enabling the shell's completion of tacher",
+				ArgsUsage: "<" + strings.Join(completion.Shells(), "|") + ">",
+				Description: "Load the script in the shell, e.g. add source <(tacher completion bash) to ~/.bashrc,\n" +
+					"source <(tacher completion zsh) to ~/.zshrc or tacher completion fish | source to\n" +
+					"~/.config/fish/config.fish. The values of the flags come from the cached metadata only.",
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 1 {
+						return errs.Input("the shell is required, one of %s", strings.Join(completion.Shells(), ", "))
+					}
+					script, err := completion.Script(ctx.Args().First(), ctx.App.Name)
+					if err != nil {
+						return err
+					}
+					fmt.Print(script)
+					return nil
+				},
+			},
+			{
+				Name:            completion.COMPLETE_COMMAND,
+				Hidden:          true,
+				SkipFlagParsing: true,
+				Action: func(ctx *cli.Context) error {
+					// completing must stay quiet, without the configuration only the flags are completed
+					var state *model.AppState
+					var servers []string
+					if cfg, err := loadConfig(ctx, nil); err == nil {
+						for name := range cfg.Prefixed(config.SERVERS) {
+							servers = append(servers, name)
+						}
+						sort.Strings(servers)
+						state = new(model.AppState)
+						if err := client.GetCachedOptions(state); err != nil {
+							state = nil
+						}
+					}
+					for _, c := range completion.Complete(ctx.App, ctx.Args().Slice(), completionValues(state, servers), []string{"dependencies", "merge"}) {
+						fmt.Println(c)
+					}
+					return nil
 				},
 			},
 		},
 	}
 	if err := app.Run(os.Args); err != nil {
-		fmt.Println(err)
+		logging.Error("tacher failed", "error", err, "exitCode", errs.ExitCode(err))
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(errs.ExitCode(err))
+	}
+}
+
+// flags of the init command and the settings they set
+var initFlags = map[string]string{
+	"group":        config.GROUP,
+	"artifact":     config.ARTIFACT,
+	"name":         config.NAME,
+	"description":  config.DESCRIPTION,
+	"package":      config.PACKAGE,
+	"type":         config.TYPE,
+	"language":     config.LANGUAGE,
+	"java":         config.JAVA_VERSION,
+	"boot":         config.BOOT_VERSION,
+	"packaging":    config.PACKAGING,
+	"dependencies": config.DEPENDENCIES,
+	"modules":      config.MODULES,
+	"path":         config.PATH,
+	"server":       config.SERVER_URL,
+	"merge":        config.SERVER_MERGE,
+	"opener":       config.UI_OPENER,
+	"editor":       config.UI_EDITOR,
+	"theme":        config.UI_THEME,
+	"policy":       config.POLICY,
+}
+
+// flags of all the commands and the settings they set
+var globalFlags = map[string]string{
+	"log-file":  config.LOG_FILE,
+	"log-level": config.LOG_LEVEL,
+}
+
+// flags of the batch command and the settings they set
+var batchFlags = map[string]string{
+	"server": config.SERVER_URL,
+	"policy": config.POLICY,
+}
+
+// flags of the metadata commands and the settings they set
+var metadataFlags = map[string]string{
+	"server": config.SERVER_URL,
+}
+
+var serverFlag = &cli.StringFlag{
+	Name:  "server",
+	Usage: "`URL` of Spring Initializr or name of one of the servers",
+}
+
+var showOriginFlag = &cli.BoolFlag{
+	Name:  "show-origin",
+	Usage: "Show where each value comes from",
+}
+
+// load the configuration, override it with the flags that are set and set up the client
+func loadConfig(ctx *cli.Context, flags map[string]string) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range []map[string]string{globalFlags, flags} {
+		for flag, key := range m {
+			if ctx.IsSet(flag) {
+				cfg.Set(key, ctx.String(flag), config.ORIGIN_FLAG+"--"+flag)
+			}
+		}
+	}
+	if err := logging.Setup(utils.ExpandPath(cfg.Get(config.LOG_FILE)), cfg.Get(config.LOG_LEVEL)); err != nil {
+		return nil, err
+	}
+	logging.Info("tacher started", "version", ctx.App.Version, "command", ctx.Command.FullName())
+
+	lang := i18n.Normalize(utils.NonNullOrElse(cfg.Get(config.UI_LANGUAGE), i18n.Detect()))
+	i18n.SetLanguage(lang)
+	client.SetLanguage(lang)
+	serverURL, err := cfg.ResolveServer(cfg.Get(config.SERVER_URL))
+	if err != nil {
+		return nil, err
+	}
+	client.SetServerURL(serverURL)
+	timeout, err := cfg.Duration(config.SERVER_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+	client.SetTimeout(timeout)
+	ttl, err := cfg.Duration(config.CACHE_TTL)
+	if err != nil {
+		return nil, err
+	}
+	client.SetCache(utils.ExpandPath(cfg.Get(config.CACHE_DIR)), ttl)
+
+	// the server's defaults are only known if its metadata was cached
+	state := new(model.AppState)
+	if err := client.GetCachedOptions(state); err == nil {
+		cfg.SetServerDefaults(state)
+	}
+	return cfg, nil
+}
+
+// the named servers sorted by name, and the servers whose dependencies are merged with the ones of
+// the server in use. A merged server given by its URL is named after its host
+func serverOptions(cfg *config.Config) ([]ui.Server, []ui.Server, error) {
+	named := cfg.Prefixed(config.SERVERS)
+	servers := make([]ui.Server, 0, len(named))
+	for name, serverURL := range named {
+		servers = append(servers, ui.Server{Name: name, URL: serverURL})
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+
+	merge := make([]ui.Server, 0)
+	for _, name := range cfg.List(config.SERVER_MERGE) {
+		serverURL, err := cfg.ResolveServer(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, found := named[name]; !found {
+			if u, err := url.Parse(serverURL); err == nil && u.Host != "" {
+				name = u.Host
+			}
+		}
+		merge = append(merge, ui.Server{Name: name, URL: serverURL})
+	}
+	return servers, merge, nil
+}
+
+// print the changes of the metadata since the time of the previous copy, as JSON if asked. A zero
+// time means that there's no previous copy
+func printChanges(ctx *cli.Context, changes *diff.Changes, since time.Time) error {
+	server := logging.Redact(client.ServerURL())
+	if ctx.Bool("json") {
+		report := struct {
+			Server       string        `json:"server"`
+			PreviousTime *time.Time    `json:"previousTime,omitempty"`
+			Changes      *diff.Changes `json:"changes"`
+		}{Server: server, Changes: changes}
+		if !since.IsZero() {
+			report.PreviousTime = &since
+		}
+		content, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(content))
+		return nil
+	}
+	if since.IsZero() {
+		fmt.Printf("No previous metadata of %s in the cache, the changes will be shown once it changes\n", server)
+		return nil
+	}
+	fmt.Printf("Metadata of %s compared with the copy fetched on %s\n", server, since.Format("2006-01-02 15:04"))
+	if changes.IsEmpty() {
+		fmt.Println("No changes")
+	}
+	changes.Write(os.Stdout)
+	return nil
+}
+
+// print a setting's line, preceded by its origin if asked
+func printSetting(ctx *cli.Context, cfg *config.Config, key string, line string) {
+	if ctx.Bool("show-origin") {
+		fmt.Printf("%s\t%s\n", utils.NonNullOrElse(cfg.Origin(key), "unset"), line)
+	} else {
+		fmt.Println(line)
+	}
+}
+
+// values of the flags completed by the shell, taken from the cached metadata that may be missing
+// and from the names of the servers
+func completionValues(state *model.AppState, servers []string) completion.Values {
+	ids := func(values []model.Value) []string {
+		return utils.Map(values, func(v model.Value) string { return v.ID })
+	}
+	return func(flag string) []string {
+		switch flag {
+		case "theme":
+			return ui.ThemeNames()
+		case "server", "merge":
+			return servers
+		}
+		if state == nil {
+			return nil
+		}
+		switch flag {
+		case "boot":
+			return ids(state.SpringVersions)
+		case "java":
+			return ids(state.JavaVersions)
+		case "language":
+			return ids(state.Languages)
+		case "packaging":
+			return ids(state.Packaging)
+		case "type":
+			return utils.Map(state.SpringBuildTools, func(v model.ValueWithDesc) string { return v.ID })
+		case "dependencies":
+			deps := make([]string, 0)
+			for _, category := range state.Dependency {
+				for _, d := range category {
+					deps = append(deps, d.ID)
+				}
+			}
+			sort.Strings(deps)
+			return deps
+		}
+		return nil
 	}
 }
@@ -17,6 +17,53 @@ type ValueWithDesc struct {
 	ID          string
 	Name        string
 	Description string
+	// range of Spring Boot versions a dependency is compatible with
+	VersionRange string
+	// links to a dependency's documentation
+	Links []Link
+	// Maven coordinates of a custom dependency, the server gives the other ones for a Spring Boot
+	// version through its dependencies endpoint
+	Coordinates *Coordinates
+	// set only for the dependencies that Spring initializer doesn't know
+	Custom *CustomDependency
+	// name of the Spring initializer instance the dependency comes from, empty for the one in use
+	Source string
+}
+
+// link to the documentation of a dependency, e.g. its reference guide or a sample
+type Link struct {
+	Rel   string
+	Href  string
+	Title string
+	// templated links contain the '{bootVersion}' placeholder
+	Templated bool
+}
+
+// Maven coordinates of a dependency
+type Coordinates struct {
+	GroupId    string
+	ArtifactId string
+	Version    string
+	Scope      string
+}
+
+// dependency defined in a local catalog. Spring initializer doesn't know it, so it's added to
+// the build file once the project is generated
+type CustomDependency struct {
+	Coordinates Coordinates
+	// range of Spring Boot versions the dependency is compatible with
+	VersionRange string
+	// BOM imported to manage the dependency's version
+	Bom *Coordinates
+	// Maven repositories of the dependency and of its BOM, besides Maven Central
+	Repositories []Repository
+}
+
+// Maven repository declared in the build file
+type Repository struct {
+	ID   string
+	Name string
+	URL  string
 }
 
 // alias to sort an array of ValueWithDesc by name
@@ -57,6 +104,12 @@ type AppState struct {
 	DefaultJavaVersion     int
 }
 
+// module of a multi-module project, generated with its own dependencies
+type Module struct {
+	Name         string
+	Dependencies []ValueWithDesc
+}
+
 // application data, contains the values used to generate the project package
 type AppData struct {
 	Group             string
@@ -70,5 +123,6 @@ type AppData struct {
 	SpringBootVersion string
 	Packaging         string
 	Dependencies      []ValueWithDesc
+	Modules           []Module
 	Path              string
 }
@@ -0,0 +1,74 @@
+package opener
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// creates the commands run by the opener, replaced by the tests
+var newCommand = exec.Command
+
+// command that opens files and URLs with the system's default application
+func DefaultCommand() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "rundll32 url.dll,FileProtocolHandler"
+	default:
+		return "xdg-open"
+	}
+}
+
+// open the target with the given command, the target is passed as the command's last argument.
+// The command is started without waiting for it to end
+func Open(command string, target string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return fmt.Errorf("no command to open %s", target)
+	}
+	cmd := newCommand(args[0], append(args[1:], target)...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("can't open %s with %s: %w", target, args[0], err)
+	}
+	// release the process' resources once it ends
+	go cmd.Wait()
+	return nil
+}
+
+// editors tried after $VISUAL and $EDITOR
+var editors = []string{"code", "idea"}
+
+// command of the editor opening the projects: the configured one, $VISUAL, $EDITOR, code or idea,
+// the first that is installed
+func EditorCommand(configured string) (string, error) {
+	candidates := append([]string{configured, os.Getenv("VISUAL"), os.Getenv("EDITOR")}, editors...)
+	for _, c := range candidates {
+		args := strings.Fields(c)
+		if len(args) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(args[0]); err == nil {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("no editor found, set one with --editor, $VISUAL or $EDITOR")
+}
+
+// open the target with the editor's command, passed as the command's last argument. The command
+// uses the terminal, tacher waits for it to end
+func Edit(command string, target string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return fmt.Errorf("no command to edit %s", target)
+	}
+	cmd := newCommand(args[0], append(args[1:], target)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("can't open %s with %s: %w", target, args[0], err)
+	}
+	return nil
+}
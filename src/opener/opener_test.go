@@ -0,0 +1,52 @@
+package opener
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// replace the commands by the helper process, recording their arguments
+func stubCommands(t *testing.T) *[][]string {
+	calls := make([][]string, 0)
+	newCommand = func(name string, args ...string) *exec.Cmd {
+		calls = append(calls, append([]string{name}, args...))
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(), "TACHER_HELPER_PROCESS=1")
+		return cmd
+	}
+	t.Cleanup(func() { newCommand = exec.Command })
+	return &calls
+}
+
+// command run in place of the editors and of the system's opener, exits right away
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("TACHER_HELPER_PROCESS") != "1" {
+		return
+	}
+	os.Exit(0)
+}
+
+func TestOpen(t *testing.T) {
+	calls := stubCommands(t)
+	if err := Open("rundll32 url.dll,FileProtocolHandler", "https://spring.io"); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"rundll32", "url.dll,FileProtocolHandler", "https://spring.io"}}; !reflect.DeepEqual(*calls, want) {
+		t.Errorf("commands = %q, want %q", *calls, want)
+	}
+	if err := Open(" ", "https://spring.io"); err == nil {
+		t.Error("Open() succeeded without a command, want an error")
+	}
+}
+
+func TestEdit(t *testing.T) {
+	calls := stubCommands(t)
+	if err := Edit("code --wait", "/tmp/demo"); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"code", "--wait", "/tmp/demo"}}; !reflect.DeepEqual(*calls, want) {
+		t.Errorf("commands = %q, want %q", *calls, want)
+	}
+}
@@ -0,0 +1,157 @@
+package overlay
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"tacher/src/utils"
+	"text/template"
+)
+
+// suffix of the overlays' files whose content is a template, it's removed from the rendered file
+const TEMPLATE_SUFFIX = ".tmpl"
+
+// data available to the overlays' templates, both in files' content and in their paths
+type Data struct {
+	Group             string
+	Artifact          string
+	Name              string
+	Description       string
+	Pkg               string
+	PkgPath           string
+	SpringBuildTool   string
+	Language          string
+	JavaVersion       string
+	SpringBootVersion string
+	Packaging         string
+	Dependencies      []string
+}
+
+// build the templates' data from the project's data, dependencies include the ones of all the modules
+func NewData(data *model.AppData) *Data {
+	ids := utils.Map(data.Dependencies, func(d model.ValueWithDesc) string { return d.ID })
+	for _, m := range data.Modules {
+		ids = append(ids, utils.Map(m.Dependencies, func(d model.ValueWithDesc) string { return d.ID })...)
+	}
+	return &Data{
+		Group:             data.Group,
+		Artifact:          data.Artifact,
+		Name:              data.Name,
+		Description:       data.Description,
+		Pkg:               data.Pkg,
+		PkgPath:           strings.ReplaceAll(data.Pkg, ".", "/"),
+		SpringBuildTool:   data.SpringBuildTool,
+		Language:          data.Language,
+		JavaVersion:       data.JavaVersion,
+		SpringBootVersion: data.SpringBootVersion,
+		Packaging:         data.Packaging,
+		Dependencies:      ids,
+	}
+}
+
+// check if the dependency with the given ID was selected
+func (d *Data) Has(id string) bool {
+	_, found := utils.Find(d.Dependencies, func(dep string) bool { return dep == id })
+	return found
+}
+
+// render the files of each overlay directory in the generated project. Only the content of the
+// files ending with the template suffix is rendered, the other files are copied as they are, like
+// CI files using the same braces. Paths are templates too, a file is skipped when any element of
+// its path renders empty
+func Apply(dirs []string, data *model.AppData) error {
+	dest := filepath.Join(data.Path, data.Artifact)
+	tplData := NewData(data)
+	for _, dir := range dirs {
+		logging.Debug("applying an overlay", "overlay", dir, "dest", dest)
+		if err := apply(dir, dest, tplData); err != nil {
+			return fmt.Errorf("can't apply overlay %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// render the files of an overlay directory in the destination directory
+func apply(dir string, dest string, data *Data) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		// render each element of the path, an empty element excludes the file or the directory
+		target, err := renderPath(rel, data)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target = filepath.Join(dest, target)
+
+		if entry.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(rel, TEMPLATE_SUFFIX) {
+			target = strings.TrimSuffix(target, TEMPLATE_SUFFIX)
+			if content, err = render(rel, string(content), data); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		logging.Debug("overlay file rendered", "file", rel, "target", target, "size", len(content))
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+}
+
+// render each element of the path, returns an empty string if any element renders empty
+func renderPath(path string, data *Data) (string, error) {
+	elements := strings.Split(filepath.ToSlash(path), "/")
+	rendered := make([]string, 0, len(elements))
+	for _, el := range elements {
+		r, err := render(path, el, data)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(string(r)) == "" {
+			return "", nil
+		}
+		rendered = append(rendered, string(r))
+	}
+	// rendered elements can contain slashes, e.g. the package's path
+	return filepath.FromSlash(strings.Join(rendered, "/")), nil
+}
+
+// render a template with the given data
+func render(name string, text string, data *Data) ([]byte, error) {
+	tpl, err := template.New(name).Funcs(template.FuncMap{"has": data.Has}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	overlay := t.TempDir()
+	files := map[string]string{
+		// CI and Helm files use the same braces as the templates
+		".github/workflows/ci.yml":                    "steps:\n  - run: deploy --token ${{ secrets.DEPLOY_TOKEN }}\n",
+		"helm/templates/deployment.yaml":              "image: {{ .Values.image }}\n",
+		"README.md.tmpl":                              "# {{.Name}}\n{{if has \"web\"}}A web application{{end}}\n",
+		"src/main/java/{{.PkgPath}}/Config.java.tmpl": "package {{.Pkg}};\n",
+		"{{if has \"data-jpa\"}}db{{end}}/init.sql":   "create table demo();\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(overlay, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dest := t.TempDir()
+	data := &Data{Name: "demo", Pkg: "com.example.demo", PkgPath: "com/example/demo", Dependencies: []string{"web"}}
+	if err := apply(overlay, dest, data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{".github/workflows/ci.yml", files[".github/workflows/ci.yml"]},
+		{"helm/templates/deployment.yaml", files["helm/templates/deployment.yaml"]},
+		{"README.md", "# demo\nA web application\n"},
+		{"src/main/java/com/example/demo/Config.java", "package com.example.demo;\n"},
+	}
+	for _, test := range tests {
+		content, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(test.file)))
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if string(content) != test.want {
+			t.Errorf("%s = %q, want %q", test.file, content, test.want)
+		}
+	}
+	for _, missing := range []string{"README.md.tmpl", "db"} {
+		if _, err := os.Stat(filepath.Join(dest, missing)); err == nil {
+			t.Errorf("%s was written", missing)
+		}
+	}
+}
+
+func TestApplyInvalidTemplate(t *testing.T) {
+	overlay := t.TempDir()
+	if err := os.WriteFile(filepath.Join(overlay, "broken.txt.tmpl"), []byte("{{.Name"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := apply(overlay, t.TempDir(), &Data{}); err == nil {
+		t.Error("apply() succeeded with an invalid template, want an error")
+	}
+}
@@ -0,0 +1,237 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"tacher/src/errs"
+	"tacher/src/i18n"
+	"tacher/src/model"
+	"tacher/src/utils"
+	"tacher/src/version"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rules that the generated projects must follow, an empty policy allows everything
+type Policy struct {
+	// lowest Java version allowed, like 17
+	MinJavaVersion string `yaml:"minJavaVersion"`
+	// range of the allowed Spring Boot versions, in Spring initializer's format
+	BootVersions string `yaml:"bootVersions"`
+	// allow milestones, release candidates and snapshots of Spring Boot
+	AllowPreReleases bool `yaml:"allowPreReleases"`
+	// dependencies that every project must have
+	RequiredDependencies []string `yaml:"requiredDependencies"`
+	// dependencies that no project can have
+	ForbiddenDependencies []string `yaml:"forbiddenDependencies"`
+	// regular expression that the artifacts must match
+	ArtifactPattern string `yaml:"artifactPattern"`
+
+	// file the policy was loaded from
+	file     string
+	artifact *regexp.Regexp
+}
+
+// load the policy file, the policy is empty if there's no file
+func Load(file string) (*Policy, error) {
+	p := new(Policy)
+	if file == "" {
+		return p, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't load the policy: %w", err)
+	}
+	if err := yaml.Unmarshal(content, p); err != nil {
+		return nil, &errs.InputError{Err: fmt.Errorf("can't parse the policy %s: %w", file, err)}
+	}
+	p.file = file
+
+	// validate the rules once, so that they can be applied without errors
+	if p.MinJavaVersion != "" {
+		if _, err := javaVersion(p.MinJavaVersion); err != nil {
+			return nil, errs.Input("policy %s: invalid minimum Java version %q", file, p.MinJavaVersion)
+		}
+	}
+	if _, err := version.ParseRange(p.BootVersions); err != nil {
+		return nil, errs.Input("policy %s: %w", file, err)
+	}
+	if p.ArtifactPattern != "" {
+		if p.artifact, err = regexp.Compile(p.ArtifactPattern); err != nil {
+			return nil, errs.Input("policy %s: invalid artifact pattern: %w", file, err)
+		}
+	}
+	for _, id := range p.RequiredDependencies {
+		if utils.Contains(p.ForbiddenDependencies, id) {
+			return nil, errs.Input("policy %s: dependency %s is both required and forbidden", file, id)
+		}
+	}
+	return p, nil
+}
+
+// check if no policy was loaded, everything is allowed
+func (p *Policy) IsEmpty() bool {
+	return p.file == ""
+}
+
+// major number of a Java version, 1.8 is 8
+func javaVersion(id string) (int, error) {
+	return strconv.Atoi(strings.TrimPrefix(id, "1."))
+}
+
+// check if the Java version is allowed
+func (p *Policy) AllowsJava(id string) bool {
+	if p.MinJavaVersion == "" {
+		return true
+	}
+	v, err := javaVersion(id)
+	min, _ := javaVersion(p.MinJavaVersion)
+	return err == nil && v >= min
+}
+
+// check if the Spring Boot version is allowed, pre-releases are only allowed explicitly
+func (p *Policy) AllowsBoot(id string) bool {
+	if p.IsEmpty() {
+		return true
+	}
+	v, err := version.Parse(id)
+	if err != nil {
+		return false
+	}
+	if v.IsPreRelease() && !p.AllowPreReleases {
+		return false
+	}
+	inRange, err := version.InRange(id, p.BootVersions)
+	return err == nil && inRange
+}
+
+// check if the dependency is allowed
+func (p *Policy) AllowsDependency(id string) bool {
+	return !utils.Contains(p.ForbiddenDependencies, id)
+}
+
+// check if the dependency is required
+func (p *Policy) Requires(id string) bool {
+	return utils.Contains(p.RequiredDependencies, id)
+}
+
+// remove the forbidden options from the app's state, the defaults move to the first allowed option.
+// Fails if the policy doesn't leave any option
+func (p *Policy) Apply(state *model.AppState) error {
+	var err error
+	if state.JavaVersions, state.DefaultJavaVersion, err = filterValues(state.JavaVersions, state.DefaultJavaVersion, p.AllowsJava); err != nil {
+		return fmt.Errorf("no Java version is allowed by the policy")
+	}
+	if state.SpringVersions, state.DefaultSpringVersion, err = filterValues(state.SpringVersions, state.DefaultSpringVersion, p.AllowsBoot); err != nil {
+		return fmt.Errorf("no Spring Boot version is allowed by the policy")
+	}
+	for category, deps := range state.Dependency {
+		allowed := make([]model.ValueWithDesc, 0, len(deps))
+		for _, d := range deps {
+			if p.AllowsDependency(d.ID) {
+				allowed = append(allowed, d)
+			}
+		}
+		if len(allowed) == 0 {
+			delete(state.Dependency, category)
+		} else {
+			state.Dependency[category] = allowed
+		}
+	}
+	return nil
+}
+
+// keep the allowed values, the default index follows its value or moves to the first allowed one
+func filterValues(values []model.Value, def int, allowed func(id string) bool) ([]model.Value, int, error) {
+	ret := make([]model.Value, 0, len(values))
+	newDef := 0
+	for i, v := range values {
+		if !allowed(v.ID) {
+			continue
+		}
+		if i == def {
+			newDef = len(ret)
+		}
+		ret = append(ret, v)
+	}
+	if len(ret) == 0 {
+		return nil, 0, errors.New("no allowed values")
+	}
+	return ret, newDef, nil
+}
+
+// list the violations of the policy, the project's modules are checked too. Returns nil if there
+// are none
+func (p *Policy) Check(data *model.AppData) error {
+	violations := make([]string, 0)
+	if !p.AllowsJava(data.JavaVersion) {
+		violations = append(violations, i18n.T("policy.violation.java", data.JavaVersion, p.MinJavaVersion))
+	}
+	if !p.AllowsBoot(data.SpringBootVersion) {
+		violations = append(violations, i18n.T("policy.violation.boot", data.SpringBootVersion))
+	}
+
+	// every module is a project with its own artifact and dependencies
+	projects := []model.Module{{Name: data.Artifact, Dependencies: data.Dependencies}}
+	if len(data.Modules) > 0 {
+		if p.artifact != nil && !p.artifact.MatchString(data.Artifact) {
+			violations = append(violations, i18n.T("policy.violation.artifact", data.Artifact, p.ArtifactPattern))
+		}
+		projects = data.Modules
+	}
+	for _, prj := range projects {
+		if p.artifact != nil && !p.artifact.MatchString(prj.Name) {
+			violations = append(violations, i18n.T("policy.violation.artifact", prj.Name, p.ArtifactPattern))
+		}
+		ids := utils.Map(prj.Dependencies, func(d model.ValueWithDesc) string { return d.ID })
+		for _, id := range p.RequiredDependencies {
+			if !utils.Contains(ids, id) {
+				violations = append(violations, i18n.T("policy.violation.required", prj.Name, id))
+			}
+		}
+		for _, id := range ids {
+			if !p.AllowsDependency(id) {
+				violations = append(violations, i18n.T("policy.violation.forbidden", prj.Name, id))
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		sort.Strings(violations)
+		return &errs.ViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// lines describing the policy's rules, empty if there are none
+func (p *Policy) Summary() []string {
+	lines := make([]string, 0)
+	if p.IsEmpty() {
+		return lines
+	}
+	lines = append(lines, i18n.T("policy.summary.file", p.file))
+	if p.MinJavaVersion != "" {
+		lines = append(lines, i18n.T("policy.summary.java", p.MinJavaVersion))
+	}
+	if p.BootVersions != "" {
+		lines = append(lines, i18n.T("policy.summary.boot", p.BootVersions))
+	}
+	if !p.AllowPreReleases {
+		lines = append(lines, i18n.T("policy.summary.no_prereleases"))
+	}
+	if len(p.RequiredDependencies) > 0 {
+		lines = append(lines, i18n.T("policy.summary.required", strings.Join(p.RequiredDependencies, ", ")))
+	}
+	if len(p.ForbiddenDependencies) > 0 {
+		lines = append(lines, i18n.T("policy.summary.forbidden", strings.Join(p.ForbiddenDependencies, ", ")))
+	}
+	if p.ArtifactPattern != "" {
+		lines = append(lines, i18n.T("policy.summary.artifact", p.ArtifactPattern))
+	}
+	return lines
+}
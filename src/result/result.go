@@ -0,0 +1,199 @@
+package result
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"tacher/src/client"
+	"tacher/src/errs"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"tacher/src/utils"
+	"time"
+)
+
+// kind of the error when the wizard is quit without generating the project
+const ERROR_CANCELLED = "cancelled"
+
+// what happened during a generation run, written as JSON for the wrapper scripts
+type Result struct {
+	Success bool `json:"success"`
+	// final data of the project, even if the generation failed
+	Project *Project `json:"project,omitempty"`
+	// Spring initializer instance in use
+	Server string `json:"server"`
+	// time when the metadata was fetched from the server, missing if it wasn't
+	MetadataTime *time.Time `json:"metadataTime,omitempty"`
+	// the metadata comes from the snapshot embedded in tacher, the server and the cache failed
+	MetadataSnapshot bool `json:"metadataSnapshot,omitempty"`
+	// directory of the generated project
+	OutputDir string `json:"outputDir,omitempty"`
+	// files written by the generation
+	Files []File `json:"files"`
+	// hooks run after the generation, always empty until tacher runs hooks so that the scripts
+	// can rely on the field
+	Hooks []Hook `json:"hooks"`
+	Error *Error `json:"error,omitempty"`
+
+	generated bool
+}
+
+// data of the project, named like Spring initializer's parameters
+type Project struct {
+	GroupId     string `json:"groupId"`
+	ArtifactId  string `json:"artifactId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	PackageName string `json:"packageName"`
+	Type        string `json:"type"`
+	Language    string `json:"language"`
+	BootVersion string `json:"bootVersion"`
+	JavaVersion string `json:"javaVersion"`
+	Packaging   string `json:"packaging"`
+	// IDs of the dependencies
+	Dependencies []string `json:"dependencies"`
+	Modules      []Module `json:"modules,omitempty"`
+	// directory where the project is created
+	Path string `json:"path"`
+}
+
+// module of a multi-module project
+type Module struct {
+	Name         string   `json:"name"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// file written in the project's directory
+type File struct {
+	// path relative to the project's directory
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// outcome of a hook run after the generation
+type Hook struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	// exit code of the command, -1 if it couldn't be started
+	ExitCode int `json:"exitCode"`
+	// error of the hook that failed
+	Error string `json:"error,omitempty"`
+}
+
+// error that ended the run
+type Error struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// HTTP status answered by the server, for the server's errors only
+	Status int `json:"status,omitempty"`
+	// message sent by the server, for the server's errors only
+	ServerMessage string `json:"serverMessage,omitempty"`
+	// field of the project the error is about and its value, when known
+	Field string `json:"field,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+func New() *Result {
+	return &Result{Files: make([]File, 0), Hooks: make([]Hook, 0)}
+}
+
+// record the final data of the project
+func (r *Result) SetProject(data *model.AppData) {
+	ids := func(deps []model.ValueWithDesc) []string {
+		return utils.Map(deps, func(d model.ValueWithDesc) string { return d.ID })
+	}
+	r.Project = &Project{
+		GroupId:      data.Group,
+		ArtifactId:   data.Artifact,
+		Name:         data.Name,
+		Description:  data.Description,
+		PackageName:  data.Pkg,
+		Type:         data.SpringBuildTool,
+		Language:     data.Language,
+		BootVersion:  data.SpringBootVersion,
+		JavaVersion:  data.JavaVersion,
+		Packaging:    data.Packaging,
+		Dependencies: ids(data.Dependencies),
+		Path:         data.Path,
+	}
+	for _, m := range data.Modules {
+		r.Project.Modules = append(r.Project.Modules, Module{Name: m.Name, Dependencies: ids(m.Dependencies)})
+	}
+}
+
+// record the outcome of the project's generation started at the given time, the last one counts
+func (r *Result) Generated(data *model.AppData, started time.Time, err error) {
+	r.SetProject(data)
+	r.Files = make([]File, 0)
+	if err != nil {
+		r.generated = false
+		r.Error = NewError(err)
+		return
+	}
+	r.generated = true
+	r.Error = nil
+	r.OutputDir = filepath.Join(data.Path, data.Artifact)
+	if abs, err := filepath.Abs(r.OutputDir); err == nil {
+		r.OutputDir = abs
+	}
+	// the files written by the generation are the ones modified since it started, the times of
+	// some file systems are rounded to the second
+	since := started.Truncate(time.Second)
+	filepath.WalkDir(r.OutputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+		rel, _ := filepath.Rel(r.OutputDir, path)
+		r.Files = append(r.Files, File{Path: filepath.ToSlash(rel), Size: info.Size()})
+		return nil
+	})
+}
+
+// complete the result once the run is over. Without an error the run was cancelled if nothing
+// was generated
+func (r *Result) Finish(err error) {
+	r.Server = logging.Redact(client.ServerURL())
+	if t := client.MetadataTime(); !t.IsZero() {
+		r.MetadataTime = &t
+	}
+	r.MetadataSnapshot = client.SnapshotInUse() != nil
+	if err != nil {
+		r.Error = NewError(err)
+	} else if !r.generated && r.Error == nil {
+		r.Error = &Error{Kind: ERROR_CANCELLED, Message: "no project was generated"}
+	}
+	r.Success = r.generated && r.Error == nil
+}
+
+// write the result in the file, or in the standard output if the file is "-"
+func (r *Result) Write(file string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	if file == "-" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	return os.WriteFile(file, content, 0644)
+}
+
+// describe the error, its kind depends on where it comes from
+func NewError(err error) *Error {
+	kind, _ := errs.Classify(err)
+	e := &Error{Kind: kind, Message: err.Error()}
+	e.Field, e.Value = errs.FieldOf(err)
+	var server *errs.ServerError
+	if errors.As(err, &server) {
+		e.Status = server.Status
+		e.ServerMessage = server.Message
+	}
+	return e
+}
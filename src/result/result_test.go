@@ -0,0 +1,45 @@
+package result
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"cancelled", nil},
+		{"failed", errors.New("boom")},
+	}
+	for _, test := range tests {
+		r := New()
+		r.Finish(test.err)
+		file := filepath.Join(t.TempDir(), "result.json")
+		if err := r.Write(file); err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(content, &fields); err != nil {
+			t.Fatalf("%s: invalid JSON: %v\n%s", test.name, err, content)
+		}
+		// the lists are there even when they're empty
+		for _, list := range []string{"files", "hooks"} {
+			if !reflect.DeepEqual(fields[list], []interface{}{}) {
+				t.Errorf("%s: %s = %v, want an empty list\n%s", test.name, list, fields[list], content)
+			}
+		}
+		if fields["success"] != false || fields["error"] == nil {
+			t.Errorf("%s: want a failure with an error\n%s", test.name, content)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"tacher/src/i18n"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// name of the node moving to the parent directory
+const BROWSER_PARENT = ".."
+
+// tree showing the subdirectories of a directory, choosing one moves into it
+type browser struct {
+	tree *tview.TreeView
+	dir  string
+	// called when the browser moves to another directory
+	changed func(dir string)
+}
+
+func newBrowser() *browser {
+	b := &browser{tree: tview.NewTreeView()}
+	b.tree.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	b.tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if dir, isDir := node.GetReference().(string); isDir {
+			b.moveTo(dir)
+		}
+	})
+	return b
+}
+
+// show the subdirectories of the given directory, hidden ones are skipped. Directories that
+// can't be read are ignored
+func (b *browser) moveTo(dir string) {
+	dir = filepath.Clean(dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil || dir == b.dir {
+		return
+	}
+	b.dir = dir
+
+	root := tview.NewTreeNode(dir).SetSelectable(false)
+	if parent := filepath.Dir(dir); parent != dir {
+		root.AddChild(tview.NewTreeNode(BROWSER_PARENT).SetReference(parent))
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		root.AddChild(tview.NewTreeNode(name + string(filepath.Separator)).SetReference(filepath.Join(dir, name)))
+	}
+	b.tree.SetRoot(root).SetCurrentNode(nil)
+	if children := root.GetChildren(); len(children) > 0 {
+		b.tree.SetCurrentNode(children[0])
+	}
+	b.tree.SetTitle(i18n.T("browser.title", len(names)))
+
+	if b.changed != nil {
+		b.changed(dir)
+	}
+}
+
+// create a folder in the current directory and move into it
+func (b *browser) mkdir(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsRune(name, filepath.Separator) {
+		return errors.New(i18n.T("error.folder_name", name))
+	}
+	dir := filepath.Join(b.dir, name)
+	if err := os.Mkdir(dir, os.ModePerm); err != nil {
+		return errors.New(i18n.T("error.folder", dir, err))
+	}
+	b.moveTo(dir)
+	return nil
+}
+
+// time to wait for the path to settle before checking it
+const PATH_CHECK_DELAY = 300 * time.Millisecond
+
+// runs the last of the calls made in a row once they stop, the calls are made from the UI's goroutine
+type debouncer struct {
+	timer   *time.Timer
+	request int
+}
+
+// call fn after the delay, unless another call comes first. fn runs in its own goroutine and
+// latest tells, from the UI's goroutine, if no call came since
+func (d *debouncer) call(delay time.Duration, fn func(latest func() bool)) {
+	d.request++
+	request := d.request
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(delay, func() { fn(func() bool { return request == d.request }) })
+}
+
+// problems that would make the generation of the project in dir/baseDir fail or overwrite files
+func checkProjectPath(dir string, baseDir string) []string {
+	warnings := make([]string, 0)
+	if dir == "" {
+		return append(warnings, i18n.T("path.empty"))
+	}
+	target := filepath.Join(dir, baseDir)
+	if _, err := os.Stat(target); err == nil {
+		warnings = append(warnings, i18n.T("path.exists", target))
+	}
+
+	// the missing directories are created, the first existing one must be writable
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil && !info.IsDir() {
+			return append(warnings, i18n.T("path.not_directory", existing))
+		} else if err == nil {
+			break
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return append(warnings, i18n.T("path.unreadable", existing, err))
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	if !writable(existing) {
+		warnings = append(warnings, i18n.T("path.not_writable", existing))
+	}
+	return warnings
+}
+
+// check if files can be created in the directory
+func writable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".tacher-")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
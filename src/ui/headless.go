@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"tacher/src/i18n"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"tacher/src/policy"
+	"tacher/src/utils"
+)
+
+// generate the project without asking anything, the values come from the parameters, the
+// configuration and Spring initializer's defaults. Fails with the violations of the policy
+// instead of fixing them
+func RunHeadless(params *model.AppData, opts Options, out io.Writer) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+	logging.Info("headless generation started")
+	rules := opts.Policy
+	opts.Policy = new(policy.Policy)
+	state, data, unmapped, hist, err := prepare(params, opts)
+	if err != nil {
+		return err
+	}
+	if w := snapshotWarning(); w != "" {
+		fmt.Fprintln(out, i18n.T("prompt.warning", w))
+	}
+	if len(unmapped) > 0 {
+		fmt.Fprintf(out, "%s\n  %s\n", i18n.T("info.unmapped", opts.Like), strings.Join(unmapped, "\n  "))
+	}
+
+	// the values that weren't given are the ones the wizard would have pre-selected
+	data.SpringBuildTool = utils.NonNullOrElse(data.SpringBuildTool, state.SpringBuildTools[state.DefaultSpringBuildTool].ID)
+	data.Language = utils.NonNullOrElse(data.Language, state.Languages[state.DefaultLanguage].ID)
+	data.SpringBootVersion = utils.NonNullOrElse(data.SpringBootVersion, state.SpringVersions[state.DefaultSpringVersion].ID)
+	data.Packaging = utils.NonNullOrElse(data.Packaging, state.Packaging[state.DefaultPackaging].ID)
+	data.JavaVersion = utils.NonNullOrElse(data.JavaVersion, state.JavaVersions[state.DefaultJavaVersion].ID)
+	if data.Path, err = filepath.Abs(utils.NonNullOrElse(data.Path, ".")); err != nil {
+		return err
+	}
+
+	if w := checkJavaVersion(data.JavaVersion, opts.JDKs); w != "" {
+		fmt.Fprintln(out, i18n.T("prompt.warning", w))
+	}
+
+	opts.Result.SetProject(data)
+	if err := rules.Check(data); err != nil {
+		return err
+	}
+	if err := generate(data, opts); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, i18n.T("info.created", filepath.Join(data.Path, data.Artifact)))
+	hist.Record(dependencyIDs(data))
+	if err := hist.Save(); err != nil {
+		fmt.Fprintln(out, i18n.T("error.history", err))
+	}
+	return afterGeneration(filepath.Join(data.Path, data.Artifact), opts.Open, opts, out)
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"tacher/src/i18n"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// actions bound to keys, their bindings can be changed in the configuration file
+const ACTION_NEXT = "next"
+const ACTION_BACK = "back"
+const ACTION_QUIT = "quit"
+const ACTION_SEARCH = "search"
+const ACTION_HELP = "help"
+const ACTION_FOCUS_NEXT = "focus-next"
+const ACTION_FOCUS_PREV = "focus-prev"
+const ACTION_PREVIEW = "preview"
+const ACTION_FAVORITE = "favorite"
+const ACTION_OPEN = "open"
+
+// prefix of the actions opening a link of the dependency by its number, from 1 to 9
+const ACTION_OPEN_LINK = "open-link-"
+
+// number of links that can be opened by their number
+const OPEN_LINK_COUNT = 9
+
+// default key of each action
+var defaultKeys = map[string]string{
+	ACTION_NEXT:       "Ctrl+N",
+	ACTION_BACK:       "Ctrl+B",
+	ACTION_QUIT:       "Ctrl+Q",
+	ACTION_SEARCH:     "/",
+	ACTION_HELP:       "?",
+	ACTION_FOCUS_NEXT: "Tab",
+	ACTION_FOCUS_PREV: "Backtab",
+	ACTION_PREVIEW:    "Ctrl+P",
+	ACTION_FAVORITE:   "f",
+	ACTION_OPEN:       "o",
+}
+
+// description of each action as a message, shown in the help
+var actionDescriptions = map[string]string{
+	ACTION_NEXT:       "action.next",
+	ACTION_BACK:       "action.back",
+	ACTION_QUIT:       "action.quit",
+	ACTION_SEARCH:     "action.search",
+	ACTION_HELP:       "action.help",
+	ACTION_FOCUS_NEXT: "action.focus_next",
+	ACTION_FOCUS_PREV: "action.focus_prev",
+	ACTION_PREVIEW:    "action.preview",
+	ACTION_FAVORITE:   "action.favorite",
+	ACTION_OPEN:       "action.open",
+}
+
+func init() {
+	for n := 1; n <= OPEN_LINK_COUNT; n++ {
+		defaultKeys[openLinkAction(n)] = fmt.Sprint(n)
+		actionDescriptions[openLinkAction(n)] = "action.open_link"
+	}
+}
+
+// action opening the link of the given number
+func openLinkAction(n int) string {
+	return fmt.Sprintf("%s%d", ACTION_OPEN_LINK, n)
+}
+
+// key bound to an action, either a special key or a character
+type keyBinding struct {
+	name string
+	key  tcell.Key
+	char rune
+}
+
+// key bindings of all the actions
+type keyMap map[string]keyBinding
+
+// build the key map from the default bindings and the ones of the configuration
+func newKeyMap(overrides map[string]string) (keyMap, error) {
+	keys := make(keyMap)
+	for action, name := range defaultKeys {
+		keys[action], _ = parseKey(name)
+	}
+	for action, name := range overrides {
+		if _, known := defaultKeys[action]; !known {
+			return nil, fmt.Errorf("unknown action %s in key bindings", action)
+		}
+		binding, err := parseKey(name)
+		if err != nil {
+			return nil, fmt.Errorf("can't bind %s: %w", action, err)
+		}
+		keys[action] = binding
+	}
+	return keys, nil
+}
+
+// parse a key like 'Ctrl+N', 'F1', 'Backtab' or '/'
+func parseKey(name string) (keyBinding, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return keyBinding{name: name, key: tcell.KeyRune, char: r}, nil
+	}
+	normalized := strings.ToLower(strings.ReplaceAll(name, "+", "-"))
+	for key, keyName := range tcell.KeyNames {
+		if strings.ToLower(keyName) == normalized {
+			return keyBinding{name: name, key: key}, nil
+		}
+	}
+	return keyBinding{}, fmt.Errorf("unknown key %s", name)
+}
+
+// check if the event matches the action's key
+func (k keyMap) matches(action string, event *tcell.EventKey) bool {
+	binding, found := k[action]
+	if !found {
+		return false
+	}
+	if binding.key == tcell.KeyRune {
+		return event.Key() == tcell.KeyRune && event.Rune() == binding.char
+	}
+	return event.Key() == binding.key
+}
+
+// check if the action is bound to a character, it can't be used while typing
+func (k keyMap) isChar(action string) bool {
+	return k[action].key == tcell.KeyRune
+}
+
+// lines describing the key bindings, sorted by action. The actions sharing a description, like
+// the ones opening the links, share a line
+func (k keyMap) help() []string {
+	actions := make([]string, 0, len(k))
+	for action := range k {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	names := make(map[string][]string)
+	descriptions := make([]string, 0, len(actions))
+	for _, action := range actions {
+		description := actionDescriptions[action]
+		if _, found := names[description]; !found {
+			descriptions = append(descriptions, description)
+		}
+		names[description] = append(names[description], k[action].name)
+	}
+	lines := make([]string, 0, len(descriptions))
+	for _, description := range descriptions {
+		lines = append(lines, fmt.Sprintf("%-10s %s", strings.Join(names[description], ","), i18n.T(description)))
+	}
+	return lines
+}
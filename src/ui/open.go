@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"tacher/src/errs"
+	"tacher/src/i18n"
+	"tacher/src/logging"
+	"tacher/src/opener"
+)
+
+// project generated by the wizard, opened once the wizard is closed if it was asked
+type generatedProject struct {
+	dir  string
+	open bool
+}
+
+// tell the shell where the generated project is and open it with the editor, as the options ask.
+// The project is generated even if the editor can't be started, so it's only reported
+func afterGeneration(dir string, open bool, opts Options, out io.Writer) error {
+	if opts.PrintCd {
+		fmt.Fprintf(out, "cd %s\n", shellQuote(dir))
+	}
+	if opts.cdFile != nil {
+		if _, err := fmt.Fprintln(opts.cdFile, dir); err != nil {
+			return errs.Input("can't write the project's directory to the file descriptor %d: %w", opts.CdFd, err)
+		}
+	}
+	if !open {
+		return nil
+	}
+	editor, err := opener.EditorCommand(opts.Editor)
+	if err == nil {
+		logging.Info("opening the project", "editor", editor, "dir", dir)
+		err = opener.Edit(editor, dir)
+	}
+	if err != nil {
+		logging.Warn("can't open the project", "error", err)
+		fmt.Fprintln(os.Stderr, i18n.T("prompt.warning", err))
+	}
+	return nil
+}
+
+// file of the descriptor where the generated project's directory is written, an input error if
+// the descriptor isn't open
+func openCdFd(fd int) (*os.File, error) {
+	file := os.NewFile(uintptr(fd), "cd-fd")
+	if file == nil {
+		return nil, errs.Input("invalid file descriptor %d", fd)
+	}
+	if _, err := file.Stat(); err != nil {
+		// the descriptor can be reused later, it mustn't be closed when the file is collected
+		file.Close()
+		return nil, errs.Input("the file descriptor %d isn't open: %w", fd, err)
+	}
+	return file, nil
+}
+
+// quote the text for the POSIX shells
+func shellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
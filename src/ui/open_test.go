@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"tacher/src/errs"
+	"testing"
+)
+
+func TestWithDefaultsChecksCdFd(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	opts, err := Options{CdFd: int(w.Fd())}.withDefaults()
+	if err != nil {
+		t.Fatalf("withDefaults() failed with an open descriptor: %v", err)
+	}
+	if err := afterGeneration("/tmp/demo", false, opts, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	// both files share the descriptor, close them together
+	opts.cdFile.Close()
+	w.Close()
+	written, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != "/tmp/demo\n" {
+		t.Errorf("written = %q, want the project's directory", written)
+	}
+
+	// a descriptor that isn't open fails before the generation
+	var input *errs.InputError
+	if _, err := (Options{CdFd: 1000}).withDefaults(); !errors.As(err, &input) {
+		t.Errorf("withDefaults() = %v with a closed descriptor, want an input error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"tacher/src/generator"
+	"tacher/src/i18n"
+	"tacher/src/model"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// time to wait for the selections to settle before fetching the build file
+const PREVIEW_DELAY = 500 * time.Millisecond
+
+// pane showing the build file that Spring initializer generates for the current selections
+type preview struct {
+	app  *tview.Application
+	data *model.AppData
+	view *tview.TextView
+
+	visible bool
+	// lines of the last fetched build file
+	lines []string
+	// lines shown when the pane was hidden, the new ones are highlighted
+	baseline []string
+
+	// pending fetch and number of the last requested one, stale responses are dropped
+	mu      sync.Mutex
+	timer   *time.Timer
+	request int
+}
+
+func newPreview(app *tview.Application, data *model.AppData) *preview {
+	view := tview.NewTextView().SetDynamicColors(true)
+	view.SetBorder(true).SetTitle(i18n.T("preview.title")).SetTitleAlign(tview.AlignLeft)
+	return &preview{app: app, data: data, view: view}
+}
+
+// show or hide the pane, returns true if the pane is now visible
+func (p *preview) toggle() bool {
+	p.visible = !p.visible
+	if p.visible {
+		p.refresh()
+	} else {
+		p.baseline = p.lines
+	}
+	return p.visible
+}
+
+// fetch the build file again once the selections settle, nothing happens if the pane is hidden
+func (p *preview) refresh() {
+	if !p.visible {
+		return
+	}
+
+	// the data is copied since the UI keeps changing it while the build file is fetched
+	data := *p.data
+	data.Dependencies = append([]model.ValueWithDesc(nil), p.data.Dependencies...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.request++
+	request := p.request
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = time.AfterFunc(PREVIEW_DELAY, func() {
+		p.app.QueueUpdateDraw(func() { p.view.SetTitle(i18n.T("preview.loading", data.SpringBuildTool)) })
+		content, err := generator.PreviewBuildFile(&data)
+
+		p.mu.Lock()
+		stale := request != p.request
+		p.mu.Unlock()
+		if stale {
+			return
+		}
+		p.app.QueueUpdateDraw(func() { p.show(data.SpringBuildTool, content, err) })
+	})
+}
+
+// show the fetched build file, highlighting the lines that weren't there when the pane was hidden
+func (p *preview) show(buildTool string, content string, err error) {
+	p.view.SetTitle(i18n.T("preview.title_tool", buildTool))
+	if err != nil {
+		p.view.SetText(currentTheme.errorText + i18n.T("preview.error", tview.Escape(err.Error())))
+		return
+	}
+
+	p.lines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	previous := make(map[string]int)
+	for _, l := range p.baseline {
+		previous[l]++
+	}
+	var b strings.Builder
+	for _, l := range p.lines {
+		if p.baseline != nil && previous[l] == 0 {
+			fmt.Fprintf(&b, "%s%s%s\n", currentTheme.highlight, tview.Escape(l), currentTheme.highlightReset)
+		} else {
+			previous[l]--
+			fmt.Fprintln(&b, tview.Escape(l))
+		}
+	}
+	p.view.SetText(b.String()).ScrollToBeginning()
+}
@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"tacher/src/client"
+	"tacher/src/errs"
+	"tacher/src/i18n"
+	"tacher/src/logging"
+	"tacher/src/model"
+	"tacher/src/policy"
+	"tacher/src/utils"
+)
+
+// maximum number of search results listed at once
+const PROMPT_MAX_RESULTS = 20
+
+// sequential wizard asking questions on a plain terminal, the answers can be piped from a file
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+	eof bool
+}
+
+// run the wizard asking the questions to out and reading the answers from in. An empty answer
+// keeps the default value, as well as the end of the input
+func RunPrompt(params *model.AppData, opts Options, in io.Reader, out io.Writer) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+	logging.Info("prompt started")
+	p := &prompter{in: bufio.NewScanner(in), out: out}
+	if servers, current := serverChoices(opts); len(servers) > 1 {
+		server, err := p.choose(i18n.T("field.server"), servers, current)
+		if err != nil {
+			return err
+		}
+		client.SetServerURL(server)
+	}
+	state, data, unmapped, hist, err := prepare(params, opts)
+	if err != nil {
+		return err
+	}
+	if w := snapshotWarning(); w != "" {
+		fmt.Fprintln(out, i18n.T("prompt.warning", w))
+	}
+	if news := metadataNews(); news != "" {
+		fmt.Fprintln(out, news)
+	}
+	if len(unmapped) > 0 {
+		fmt.Fprintf(out, "%s\n  %s\n", i18n.T("info.unmapped", opts.Like), strings.Join(unmapped, "\n  "))
+	}
+
+	// project
+	p.section(PAGE_INTRO)
+	buildTools := utils.Map(state.SpringBuildTools, func(st model.ValueWithDesc) model.Value { return model.Value{ID: st.ID, Name: st.Name} })
+	if data.SpringBuildTool, err = p.choose(i18n.T("field.project"), buildTools, state.DefaultSpringBuildTool); err != nil {
+		return err
+	}
+	if data.Language, err = p.choose(i18n.T("field.language"), state.Languages, state.DefaultLanguage); err != nil {
+		return err
+	}
+	if data.SpringBootVersion, err = p.choose(i18n.T("field.boot"), state.SpringVersions, state.DefaultSpringVersion); err != nil {
+		return err
+	}
+
+	// metadata
+	p.section(PAGE_PRJ_META)
+	data.Group = p.ask(i18n.T("field.group"), data.Group)
+	data.Artifact = p.ask(i18n.T("field.artifact"), data.Artifact)
+	data.Name = p.ask(i18n.T("field.name"), data.Name)
+	data.Description = p.ask(i18n.T("field.description"), data.Description)
+	data.Pkg = p.ask(i18n.T("field.package"), data.Pkg)
+	if data.Packaging, err = p.choose(i18n.T("field.packaging"), state.Packaging, state.DefaultPackaging); err != nil {
+		return err
+	}
+	if data.JavaVersion, err = p.choose(i18n.T("field.java"), markInstalled(state.JavaVersions, opts.JDKs), state.DefaultJavaVersion); err != nil {
+		return err
+	}
+
+	// dependencies
+	p.section(PAGE_DEPENDENCIES)
+	if len(hist.Favorites) > 0 {
+		fmt.Fprintf(out, "%s: %s\n", i18n.T(CATEGORY_FAVORITES), strings.Join(hist.Favorites, ", "))
+	}
+	if len(hist.Recent) > 0 {
+		fmt.Fprintf(out, "%s: %s\n", i18n.T(CATEGORY_RECENT), strings.Join(hist.Recent, ", "))
+	}
+	if err := p.chooseDependencies(state, data, opts.Policy); err != nil {
+		return err
+	}
+
+	// path and generation
+	p.section(PAGE_PRJ_PATH)
+	if data.Path == "" {
+		data.Path, _ = os.UserHomeDir()
+	}
+	data.Path = utils.ExpandPath(p.ask(i18n.T("field.path"), data.Path))
+	for _, line := range opts.Policy.Summary() {
+		fmt.Fprintf(out, "%s\n", line)
+	}
+	opts.Result.SetProject(data)
+	if err := opts.Policy.Check(data); err != nil {
+		return err
+	}
+	warnings := checkProjectPath(data.Path, data.Artifact)
+	if w := checkJavaVersion(data.JavaVersion, opts.JDKs); w != "" {
+		warnings = append(warnings, w)
+	}
+	for _, w := range warnings {
+		fmt.Fprintln(out, i18n.T("prompt.warning", w))
+	}
+	if err := generate(data, opts); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, i18n.T("info.created", filepath.Join(data.Path, data.Artifact)))
+	hist.Record(dependencyIDs(data))
+	if err := hist.Save(); err != nil {
+		fmt.Fprintln(out, i18n.T("error.history", err))
+	}
+	return afterGeneration(filepath.Join(data.Path, data.Artifact), opts.Open, opts, out)
+}
+
+// print the title of the page grouping the next questions
+func (p *prompter) section(page string) {
+	fmt.Fprintf(p.out, "\n== %s ==\n", i18n.T(pageTitles[page]))
+}
+
+// read the next answer, trimmed. The answer is empty once the input ends
+func (p *prompter) read() string {
+	if p.eof || !p.in.Scan() {
+		p.eof = true
+		fmt.Fprintln(p.out)
+		return ""
+	}
+	return strings.TrimSpace(p.in.Text())
+}
+
+// ask a free text question, an empty answer keeps the default value
+func (p *prompter) ask(question string, def string) string {
+	fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	return utils.NonNullOrElse(p.read(), def)
+}
+
+// ask to choose one of the values by number, ID or name, returns the chosen value's ID
+func (p *prompter) choose(question string, values []model.Value, def int) (string, error) {
+	if len(values) == 0 {
+		return "", errs.Input("no values to choose the %s from", strings.ToLower(question))
+	}
+	invalid := ""
+	for {
+		fmt.Fprintf(p.out, "%s:\n", question)
+		for i, v := range values {
+			marker := " "
+			if i == def {
+				marker = "*"
+			}
+			fmt.Fprintf(p.out, " %s %2d) %s\n", marker, i+1, v.Name)
+		}
+		fmt.Fprintf(p.out, "%s [%s]: ", i18n.T("prompt.choice"), values[def].Name)
+		answer := p.read()
+		if answer == "" && p.eof && invalid != "" {
+			// a script with a wrong answer must not silently get the default
+			return "", errs.Input("invalid %s %q", strings.ToLower(question), invalid)
+		} else if answer == "" {
+			return values[def].ID, nil
+		}
+		if idx, found := findValue(values, answer); found {
+			return values[idx].ID, nil
+		}
+		invalid = answer
+		fmt.Fprintln(p.out, i18n.T("prompt.invalid", answer))
+	}
+}
+
+// find a value by its number in the list, its ID or its name
+func findValue(values []model.Value, answer string) (int, bool) {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(values) {
+		return n - 1, true
+	}
+	return utils.Find(values, func(v model.Value) bool {
+		return strings.EqualFold(v.ID, answer) || strings.EqualFold(v.Name, answer)
+	})
+}
+
+// search the dependencies by typing, each chosen dependency is selected or deselected. An empty
+// answer ends the selection
+func (p *prompter) chooseDependencies(state *model.AppState, data *model.AppData, rules *policy.Policy) error {
+	all := make([]model.ValueWithDesc, 0)
+	for _, k := range sortedKeys(state.Dependency) {
+		for _, d := range state.Dependency[k] {
+			if _, found := utils.Find(all, func(a model.ValueWithDesc) bool { return a.ID == d.ID }); !found {
+				all = append(all, d)
+			}
+		}
+	}
+
+	sel := newSelection(data, rules.Requires)
+	for {
+		p.printSelected(data)
+		fmt.Fprintf(p.out, "%s: ", i18n.T("prompt.search"))
+		query := p.read()
+		if query == "" {
+			return nil
+		}
+
+		// an exact ID is toggled right away, otherwise the matching dependencies are listed
+		if idx, found := utils.Find(all, func(d model.ValueWithDesc) bool { return strings.EqualFold(d.ID, query) }); found {
+			sel.toggle(all[idx])
+			continue
+		}
+		matches := make([]model.ValueWithDesc, 0)
+		for _, d := range all {
+			if strings.Contains(strings.ToLower(d.Name+" "+d.ID+" "+d.Description), strings.ToLower(query)) {
+				matches = append(matches, d)
+			}
+		}
+		switch {
+		case len(matches) == 0:
+			fmt.Fprintln(p.out, i18n.T("prompt.no_match", query))
+		case len(matches) == 1:
+			sel.toggle(matches[0])
+		default:
+			if len(matches) > PROMPT_MAX_RESULTS {
+				fmt.Fprintln(p.out, i18n.T("prompt.too_many", len(matches), query, PROMPT_MAX_RESULTS))
+				matches = matches[:PROMPT_MAX_RESULTS]
+			}
+			for i, d := range matches {
+				fmt.Fprintf(p.out, "  %2d) %s (%s)\n", i+1, dependencyName(d), d.ID)
+			}
+			fmt.Fprintf(p.out, "%s: ", i18n.T("prompt.number"))
+			answer := p.read()
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(matches) {
+				sel.toggle(matches[n-1])
+			} else if answer != "" {
+				fmt.Fprintln(p.out, i18n.T("prompt.invalid", answer))
+			}
+		}
+	}
+}
+
+// keys of the map, sorted
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// print the selected dependencies
+func (p *prompter) printSelected(data *model.AppData) {
+	if len(data.Dependencies) == 0 {
+		fmt.Fprintln(p.out, i18n.T("prompt.none_selected"))
+		return
+	}
+	names := utils.Map(data.Dependencies, func(d model.ValueWithDesc) string { return fmt.Sprintf("%c %s", SELECTED_SYMBOL, dependencyName(d)) })
+	fmt.Fprintf(p.out, "%s: %s\n", i18n.T("prompt.selected"), strings.Join(names, ", "))
+}
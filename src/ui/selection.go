@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"tacher/src/model"
+)
+
+// dependencies selected by the user, keyed by ID. The project's data is kept in sync and the
+// listener is called for every change, so that every view of the selection agrees
+type selection struct {
+	data     *model.AppData
+	ids      map[string]bool
+	listener func(d model.ValueWithDesc, selected bool)
+	// dependencies that can't be deselected
+	locked func(id string) bool
+}
+
+// build the selection from the project's data, duplicated dependencies are dropped
+func newSelection(data *model.AppData, locked func(id string) bool) *selection {
+	s := &selection{data: data, ids: make(map[string]bool), locked: locked}
+	deps := data.Dependencies
+	data.Dependencies = nil
+	for _, d := range deps {
+		s.add(d)
+	}
+	return s
+}
+
+// check if the dependency is selected
+func (s *selection) has(id string) bool {
+	return s.ids[id]
+}
+
+// select the dependency, nothing happens if it's already selected
+func (s *selection) add(d model.ValueWithDesc) {
+	if s.ids[d.ID] {
+		return
+	}
+	s.ids[d.ID] = true
+	s.data.Dependencies = append(s.data.Dependencies, d)
+	s.notify(d, true)
+}
+
+// deselect the dependency, nothing happens if it isn't selected or if it's locked
+func (s *selection) remove(id string) {
+	if !s.ids[id] || s.locked(id) {
+		return
+	}
+	delete(s.ids, id)
+	for i, d := range s.data.Dependencies {
+		if d.ID == id {
+			s.data.Dependencies = append(s.data.Dependencies[:i], s.data.Dependencies[i+1:]...)
+			s.notify(d, false)
+			return
+		}
+	}
+}
+
+// select the dependency or deselect it if it's already selected
+func (s *selection) toggle(d model.ValueWithDesc) {
+	if s.ids[d.ID] {
+		s.remove(d.ID)
+	} else {
+		s.add(d)
+	}
+}
+
+// deselect all the dependencies, except the locked ones
+func (s *selection) clear() {
+	for _, d := range append([]model.ValueWithDesc(nil), s.data.Dependencies...) {
+		s.remove(d.ID)
+	}
+}
+
+func (s *selection) notify(d model.ValueWithDesc, selected bool) {
+	if s.listener != nil {
+		s.listener(d, selected)
+	}
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"reflect"
+	"tacher/src/model"
+	"testing"
+)
+
+// dependency with the given ID
+func dep(id string) model.ValueWithDesc {
+	return model.ValueWithDesc{ID: id, Name: id}
+}
+
+// IDs of the selected dependencies, in their order
+func selectedIDs(s *selection) []string {
+	ids := []string{}
+	for _, d := range s.data.Dependencies {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+func TestSelection(t *testing.T) {
+	// the policy requires security
+	locked := func(id string) bool { return id == "security" }
+	tests := []struct {
+		name    string
+		initial []string
+		change  func(s *selection)
+		want    []string
+		// changes sent to the listener, "+" for a selected dependency and "-" for a deselected one
+		events []string
+	}{
+		{"duplicates dropped", []string{"web", "web", "security"}, func(s *selection) {}, []string{"web", "security"}, []string{}},
+		{"add", []string{"web"}, func(s *selection) { s.add(dep("data-jpa")) }, []string{"web", "data-jpa"}, []string{"+data-jpa"}},
+		{"add a selected one", []string{"web"}, func(s *selection) { s.add(dep("web")) }, []string{"web"}, []string{}},
+		{"remove", []string{"web", "data-jpa"}, func(s *selection) { s.remove("web") }, []string{"data-jpa"}, []string{"-web"}},
+		{"remove an unselected one", []string{"web"}, func(s *selection) { s.remove("data-jpa") }, []string{"web"}, []string{}},
+		{"remove a locked one", []string{"web", "security"}, func(s *selection) { s.remove("security") }, []string{"web", "security"}, []string{}},
+		{"toggle", []string{"web"}, func(s *selection) {
+			s.toggle(dep("web"))
+			s.toggle(dep("data-jpa"))
+		}, []string{"data-jpa"}, []string{"-web", "+data-jpa"}},
+		{"toggle a locked one", []string{"security"}, func(s *selection) { s.toggle(dep("security")) }, []string{"security"}, []string{}},
+		{"clear all", []string{"web", "security", "data-jpa"}, func(s *selection) { s.clear() }, []string{"security"}, []string{"-web", "-data-jpa"}},
+		{"clear an empty selection", []string{}, func(s *selection) { s.clear() }, []string{}, []string{}},
+	}
+	for _, test := range tests {
+		data := &model.AppData{}
+		for _, id := range test.initial {
+			data.Dependencies = append(data.Dependencies, dep(id))
+		}
+		s := newSelection(data, locked)
+		events := []string{}
+		s.listener = func(d model.ValueWithDesc, selected bool) {
+			if selected {
+				events = append(events, "+"+d.ID)
+			} else {
+				events = append(events, "-"+d.ID)
+			}
+		}
+		test.change(s)
+
+		if got := selectedIDs(s); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: selected = %q, want %q", test.name, got, test.want)
+		}
+		if !reflect.DeepEqual(events, test.events) {
+			t.Errorf("%s: events = %q, want %q", test.name, events, test.events)
+		}
+		// the IDs agree with the project's data
+		for _, id := range test.want {
+			if !s.has(id) {
+				t.Errorf("%s: %s isn't selected", test.name, id)
+			}
+		}
+		if len(s.ids) != len(test.want) {
+			t.Errorf("%s: %d IDs selected, want %d", test.name, len(s.ids), len(test.want))
+		}
+	}
+}
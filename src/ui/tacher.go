@@ -1,13 +1,28 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path"
-	"sort"
+	"path/filepath"
+	"strings"
+	"tacher/src/buildfile"
+	"tacher/src/catalog"
 	"tacher/src/client"
+	"tacher/src/diff"
+	"tacher/src/errs"
+	"tacher/src/generator"
+	"tacher/src/history"
+	"tacher/src/i18n"
+	"tacher/src/jdk"
+	"tacher/src/logging"
 	"tacher/src/model"
+	"tacher/src/opener"
+	"tacher/src/policy"
+	"tacher/src/result"
 	"tacher/src/utils"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -16,121 +31,758 @@ import (
 const PAGE_INTRO = "Intro"
 const PAGE_PRJ_META = "Project Metadata"
 const PAGE_DEPENDENCIES = "Dependencies"
+const PAGE_MODULES = "Modules"
 const PAGE_PRJ_PATH = "Project Path"
+const CATEGORY_FAVORITES = "category.favorites"
+const CATEGORY_RECENT = "category.recent"
 const INITIAL_PAGE = PAGE_INTRO
 
-func RunUI(group, artifact, name, description, pkg string) error {
-	// init app's state and retrieve options from Spring initializer
-	state := new(model.AppState)
-	err := client.GetOptions(state)
+// options of the wizard, set from the command line
+type Options struct {
+	// values of the configuration, weaker than the existing project and the parameters
+	Defaults *model.AppData
+	// directory of an existing project whose settings pre-fill the wizard
+	Like string
+	// preset file defining the modules of a multi-module project
+	Modules string
+	// local catalogs of dependencies merged with Spring initializer's ones
+	Catalogs []string
+	// command opening the dependencies' documentation, the system's opener by default
+	Opener string
+	// rules that the project must follow
+	Policy *policy.Policy
+	// key bindings overriding the default ones, by action
+	Keys map[string]string
+	// name of the UI's theme
+	Theme string
+	// options applied to the generation of the project
+	Generation generator.Options
+	// outcome of the run, recorded for the scripts
+	Result *result.Result
+	// JDKs installed on the machine, the most recent one that Spring initializer offers is the
+	// default Java version
+	JDKs []jdk.JDK
+	// open the generated project with the editor without asking
+	Open bool
+	// command of the editor opening the generated project, found by the opener by default
+	Editor string
+	// print a cd line to the generated project, for the shell wrappers
+	PrintCd bool
+	// file descriptor where the generated project's directory is written, 0 for none
+	CdFd int
+	// file of the descriptor, opened with the defaults
+	cdFile *os.File
+	// named Spring initializer instances offered on the intro page, sorted by name
+	Servers []Server
+	// Spring initializer instances whose dependencies are merged with the ones of the instance in use
+	Merge []Server
+}
+
+// named Spring initializer instance
+type Server struct {
+	Name string
+	URL  string
+}
+
+// actions of a page, triggered by its buttons and by the key bindings. Missing actions are ignored
+type navigation struct {
+	next   func()
+	back   func()
+	search func()
+	// called every time the page is shown
+	shown func()
+	// highlight the field holding the value rejected by Spring initializer and focus it, an empty
+	// field removes the highlight
+	reject func(field string, value string)
+}
+
+// pages holding the fields of the project
+var fieldPages = map[string]string{
+	errs.FIELD_TYPE:         PAGE_INTRO,
+	errs.FIELD_LANGUAGE:     PAGE_INTRO,
+	errs.FIELD_BOOT_VERSION: PAGE_INTRO,
+	errs.FIELD_PACKAGING:    PAGE_PRJ_META,
+	errs.FIELD_JAVA_VERSION: PAGE_PRJ_META,
+	errs.FIELD_DEPENDENCIES: PAGE_DEPENDENCIES,
+}
+
+// titles of the pages, as messages
+var pageTitles = map[string]string{
+	PAGE_INTRO:        "page.intro",
+	PAGE_PRJ_META:     "page.metadata",
+	PAGE_DEPENDENCIES: "page.dependencies",
+	PAGE_MODULES:      "page.modules",
+	PAGE_PRJ_PATH:     "page.path",
+}
+
+// keys handled by each page as messages, shown in the help together with the global ones
+var pageHelp = map[string][]string{
+	PAGE_DEPENDENCIES: {
+		"help.dependencies.select",
+		"help.dependencies.remove",
+	},
+	PAGE_PRJ_PATH: {
+		"help.path.enter",
+	},
+	PAGE_MODULES: {
+		"help.modules.delete",
+		"help.modules.escape",
+	},
+}
+
+func RunUI(params *model.AppData, opts Options, out io.Writer) error {
+	opts, err := opts.withDefaults()
 	if err != nil {
 		return err
 	}
+	// the wizard starts again with the metadata of the server chosen on the intro page
+	for {
+		server, err := runUI(params, opts, out)
+		if err != nil || server == "" {
+			return err
+		}
+		logging.Info("server changed", "server", logging.Redact(server))
+		client.SetServerURL(server)
+	}
+}
 
-	// init data from parameters
-	data := new(model.AppData)
-	data.Group = utils.NonNullOrElse(group, state.DefaultGroupId)
-	data.Artifact = utils.NonNullOrElse(artifact, state.DefaultArtifactId)
-	data.Name = utils.NonNullOrElse(artifact, state.DefaultName)
-	data.Description = utils.NonNullOrElse(description, state.DefaultDescription)
-	data.Pkg = utils.NonNullOrElse(pkg, state.DefaultPackageName)
+// run the wizard until it's closed, returns the URL of the server chosen on the intro page if it
+// was closed to change the server. The cd line to the generated project is printed to out
+func runUI(params *model.AppData, opts Options, out io.Writer) (string, error) {
+	state, data, unmapped, hist, err := prepare(params, opts)
+	if err != nil {
+		return "", err
+	}
+
+	keys, err := newKeyMap(opts.Keys)
+	if err != nil {
+		return "", err
+	}
+	if err := setTheme(opts.Theme); err != nil {
+		return "", err
+	}
 
-	// init app gui
+	// init app gui, each page can go back and forth
 	state.App = tview.NewApplication()
 	state.Pages = tview.NewPages()
-	state.Pages.AddPage(PAGE_INTRO, buildIntroForm(state, data), true, false)
-	state.Pages.AddPage(PAGE_PRJ_META, buildProjectMetadataForm(state, data), true, false)
-	state.Pages.AddPage(PAGE_DEPENDENCIES, buildDependenciesPage(state, data), true, false)
-	state.Pages.AddPage(PAGE_PRJ_PATH, buildProjectPathPage(state, data), true, false)
+	nav := map[string]*navigation{
+		PAGE_INTRO:        {next: switchTo(state, PAGE_PRJ_META)},
+		PAGE_PRJ_META:     {next: switchTo(state, PAGE_DEPENDENCIES), back: switchTo(state, PAGE_INTRO)},
+		PAGE_DEPENDENCIES: {next: switchTo(state, PAGE_PRJ_PATH), back: switchTo(state, PAGE_PRJ_META)},
+		PAGE_MODULES:      {next: switchTo(state, PAGE_DEPENDENCIES), back: switchTo(state, PAGE_DEPENDENCIES)},
+		PAGE_PRJ_PATH:     {back: switchTo(state, PAGE_DEPENDENCIES)},
+	}
+	server := ""
+	state.Pages.AddPage(PAGE_INTRO, withBanner(buildIntroForm(state, data, nav[PAGE_INTRO], opts, &server), metadataNews()), true, false)
+	state.Pages.AddPage(PAGE_PRJ_META, buildProjectMetadataForm(state, data, nav[PAGE_PRJ_META], opts), true, false)
+	state.Pages.AddPage(PAGE_DEPENDENCIES, buildDependenciesPage(state, data, nav[PAGE_DEPENDENCIES], keys, hist, opts), true, false)
+	state.Pages.AddPage(PAGE_MODULES, buildModulesPage(state, data, nav[PAGE_MODULES], keys), true, false)
+	project := new(generatedProject)
+	state.Pages.AddPage(PAGE_PRJ_PATH, buildProjectPathPage(state, data, nav, keys, hist, opts, project), true, false)
 	state.Pages.SwitchToPage(INITIAL_PAGE)
+	state.App.SetRoot(state.Pages, true).SetFocus(state.Pages)
+	state.Pages.SetChangedFunc(func() {
+		page, _ := state.Pages.GetFrontPage()
+		logging.Debug("page shown", "page", page)
+		if nav[page] != nil && nav[page].shown != nil {
+			nav[page].shown()
+		}
+	})
+	state.App.SetInputCapture(globalKeys(state, nav, keys))
+
+	// report the stale metadata and what couldn't be taken from the existing project
+	messages := make([]string, 0)
+	if w := snapshotWarning(); w != "" {
+		messages = append(messages, w)
+	}
+	if len(unmapped) > 0 {
+		messages = append(messages, i18n.T("info.unmapped", opts.Like)+"\n\n"+strings.Join(unmapped, "\n"))
+	}
+	if len(messages) > 0 {
+		showInfo(state, strings.Join(messages, "\n\n"), nil)
+	}
 
 	// run gui
-	if err := state.App.SetRoot(state.Pages, true).SetFocus(state.Pages).Run(); err != nil {
+	logging.Info("wizard started")
+	if err := state.App.Run(); err != nil {
+		return "", err
+	}
+	logging.Info("wizard closed")
+	if project.dir == "" {
+		return server, nil
+	}
+	return "", afterGeneration(project.dir, project.open, opts, out)
+}
+
+// retrieve the options from Spring initializer and pre-fill the project's data, shared by the
+// full-screen UI and the prompt. Returns the settings of the existing project that couldn't be mapped
+func prepare(params *model.AppData, opts Options) (*model.AppState, *model.AppData, []string, *history.History, error) {
+	// init app's state and retrieve options from Spring initializer
+	state := new(model.AppState)
+	err := client.GetOptions(state)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if err := catalog.Merge(opts.Catalogs, state); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for _, s := range opts.Merge {
+		if client.NormalizeURL(s.URL) == client.ServerURL() {
+			continue
+		}
+		deps, err := client.GetServerDependencies(s.URL)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("can't merge the dependencies of %s: %w", s.Name, err)
+		}
+		catalog.MergeServer(s.Name, deps, state)
+	}
+	logging.Debug("options loaded", "server", logging.Redact(client.ServerURL()), "bootVersions", len(state.SpringVersions),
+		"javaVersions", len(state.JavaVersions), "categories", len(state.Dependency), "catalogs", len(opts.Catalogs), "merged", len(opts.Merge))
+
+	// pre-fill data from the configuration, then from an existing project, then from parameters
+	var like *model.AppData
+	var unmapped []string
+	if opts.Like != "" {
+		project, err := buildfile.Parse(opts.Like)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("can't read the project in %s: %w", opts.Like, err)
+		}
+		like, unmapped = project.ToAppData(state, client.GetDependencyCoordinates)
+	}
+	data := layered(opts.Defaults, like, params)
+	data.Group = utils.NonNullOrElse(data.Group, state.DefaultGroupId)
+	data.Artifact = utils.NonNullOrElse(data.Artifact, state.DefaultArtifactId)
+	data.Name = utils.NonNullOrElse(data.Name, state.DefaultName)
+	data.Description = utils.NonNullOrElse(data.Description, state.DefaultDescription)
+	data.Pkg = utils.NonNullOrElse(data.Pkg, state.DefaultPackageName)
+	if data.Dependencies, err = generator.ResolveDependencies(utils.Map(data.Dependencies, func(d model.ValueWithDesc) string { return d.ID }), state); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// hide what the policy forbids and select what it requires
+	if err := enforcePolicy(state, data, opts.Policy); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if data.JavaVersion == "" {
+		data.JavaVersion = installedJavaVersion(state, opts.JDKs)
+	}
+	selectDefaults(state, data)
+	if opts.Modules != "" {
+		if data.Modules, err = generator.LoadModules(opts.Modules, state); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+
+	// load the favorites and the recently used dependencies
+	hist, err := history.Load()
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("can't load the history: %w", err)
+	}
+	return state, data, unmapped, hist, nil
+}
+
+// servers that can be chosen, their ID is the URL. The server in use is added if it's not named,
+// returns its index too
+func serverChoices(opts Options) ([]model.Value, int) {
+	values := make([]model.Value, 0, len(opts.Servers)+1)
+	current := -1
+	for i, s := range opts.Servers {
+		values = append(values, model.Value{ID: client.NormalizeURL(s.URL), Name: s.Name})
+		if values[i].ID == client.ServerURL() && current < 0 {
+			current = i
+		}
+	}
+	if current < 0 && len(values) > 0 {
+		current = len(values)
+		values = append(values, model.Value{ID: client.ServerURL(), Name: logging.Redact(client.ServerURL())})
+	}
+	return values, utils.Max(current, 0)
+}
+
+// warning about the metadata of the embedded snapshot, empty if the metadata doesn't come from it
+func snapshotWarning() string {
+	s := client.SnapshotInUse()
+	if s == nil {
+		return ""
+	}
+	return i18n.T("info.snapshot", s.Server, s.Time.Format("2006-01-02"))
+}
+
+// short text about what changed in Spring initializer's metadata since the cached copy that this
+// run replaced, empty if it wasn't replaced
+func metadataNews() string {
+	if !client.OptionsReplaced() {
+		return ""
+	}
+	previous, current := new(model.AppState), new(model.AppState)
+	if _, err := client.GetPreviousOptions(previous); err != nil {
+		return ""
+	}
+	if err := client.GetCachedOptions(current); err != nil {
+		return ""
+	}
+	changes := diff.Compare(previous, current)
+	if changes.IsEmpty() {
+		return ""
+	}
+	if highlights := changes.Highlights(); len(highlights) > 0 {
+		return i18n.T("news.added", strings.Join(highlights, ", "))
+	}
+	return i18n.T("news.changed")
+}
+
+// show the text above the page, the page is returned as it is without text
+func withBanner(page tview.Primitive, text string) tview.Primitive {
+	if text == "" {
+		return page
+	}
+	banner := tview.NewTextView().SetDynamicColors(true).SetWrap(true).
+		SetText(currentTheme.highlight + tview.Escape(text) + currentTheme.highlightReset)
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(banner, 2, 0, false).
+		AddItem(page, 0, 1, true)
+}
+
+// fill the options that weren't set. The file descriptor of the generated project's directory is
+// checked before anything is generated
+func (opts Options) withDefaults() (Options, error) {
+	if opts.CdFd > 0 && opts.cdFile == nil {
+		file, err := openCdFd(opts.CdFd)
+		if err != nil {
+			return opts, err
+		}
+		opts.cdFile = file
+	}
+	opts.Opener = utils.NonNullOrElse(opts.Opener, opener.DefaultCommand())
+	if opts.Policy == nil {
+		opts.Policy = new(policy.Policy)
+	}
+	if opts.Result == nil {
+		opts.Result = result.New()
+	}
+	return opts, nil
+}
+
+// generate the project and record the outcome in the run's result
+func generate(data *model.AppData, opts Options) error {
+	started := time.Now()
+	err := generator.Generate(data, opts.Generation)
+	opts.Result.Generated(data, started, err)
+	return err
+}
+
+// remove the options that the policy forbids from the state and from the data, then add the
+// dependencies it requires to the data
+func enforcePolicy(state *model.AppState, data *model.AppData, p *policy.Policy) error {
+	if p == nil || p.IsEmpty() {
+		return nil
+	}
+	required, err := generator.ResolveDependencies(p.RequiredDependencies, state)
+	if err != nil {
+		return fmt.Errorf("%s: %w", i18n.T("policy.unknown_required"), err)
+	}
+	if err := p.Apply(state); err != nil {
 		return err
 	}
+
+	deps := make([]model.ValueWithDesc, 0, len(data.Dependencies)+len(required))
+	for _, d := range append(required, data.Dependencies...) {
+		_, found := utils.Find(deps, func(dep model.ValueWithDesc) bool { return dep.ID == d.ID })
+		if !found && p.AllowsDependency(d.ID) {
+			deps = append(deps, d)
+		}
+	}
+	data.Dependencies = deps
 	return nil
 }
 
-func buildIntroForm(state *model.AppState, data *model.AppData) *tview.Form {
+// most recent Java version offered by Spring initializer that is installed, empty if there's none
+func installedJavaVersion(state *model.AppState, jdks []jdk.JDK) string {
+	for _, j := range jdks {
+		idx, found := utils.Find(state.JavaVersions, func(v model.Value) bool {
+			version, err := jdk.MajorVersion(v.ID)
+			return err == nil && version == j.Version
+		})
+		if found {
+			return state.JavaVersions[idx].ID
+		}
+	}
+	return ""
+}
+
+// Java versions whose names tell if they're installed
+func markInstalled(versions []model.Value, jdks []jdk.JDK) []model.Value {
+	return utils.Map(versions, func(v model.Value) model.Value {
+		if jdk.Installed(jdks, v.ID) {
+			v.Name = i18n.T("java.installed", v.Name)
+		}
+		return v
+	})
+}
+
+// warning about a Java version that isn't installed, empty if it is
+func checkJavaVersion(version string, jdks []jdk.JDK) string {
+	if version == "" || jdk.Installed(jdks, version) {
+		return ""
+	}
+	return i18n.T("java.not_installed", version)
+}
+
+// merge the project's data of the layers, from the weakest to the strongest. Nil layers are skipped
+func layered(layers ...*model.AppData) *model.AppData {
+	data := new(model.AppData)
+	for _, l := range layers {
+		if l != nil {
+			mergeData(data, l)
+		}
+	}
+	return data
+}
+
+// copy the values that are set in src to dst
+func mergeData(dst *model.AppData, src *model.AppData) {
+	dst.Group = utils.NonNullOrElse(src.Group, dst.Group)
+	dst.Artifact = utils.NonNullOrElse(src.Artifact, dst.Artifact)
+	dst.Name = utils.NonNullOrElse(src.Name, dst.Name)
+	dst.Description = utils.NonNullOrElse(src.Description, dst.Description)
+	dst.Pkg = utils.NonNullOrElse(src.Pkg, dst.Pkg)
+	dst.SpringBuildTool = utils.NonNullOrElse(src.SpringBuildTool, dst.SpringBuildTool)
+	dst.Language = utils.NonNullOrElse(src.Language, dst.Language)
+	dst.JavaVersion = utils.NonNullOrElse(src.JavaVersion, dst.JavaVersion)
+	dst.SpringBootVersion = utils.NonNullOrElse(src.SpringBootVersion, dst.SpringBootVersion)
+	dst.Packaging = utils.NonNullOrElse(src.Packaging, dst.Packaging)
+	dst.Path = utils.NonNullOrElse(src.Path, dst.Path)
+	if len(src.Dependencies) > 0 {
+		dst.Dependencies = src.Dependencies
+	}
+	if len(src.Modules) > 0 {
+		dst.Modules = src.Modules
+	}
+}
+
+// handler of the keys that work on every page
+func globalKeys(state *model.AppState, nav map[string]*navigation, keys keyMap) func(event *tcell.EventKey) *tcell.EventKey {
+	return func(event *tcell.EventKey) *tcell.EventKey {
+		// modals handle their own keys
+		if !state.Pages.HasFocus() {
+			return event
+		}
+		// characters are typed in the input fields
+		_, typing := state.App.GetFocus().(*tview.InputField)
+		triggered := func(action string) bool {
+			return keys.matches(action, event) && !(typing && keys.isChar(action))
+		}
+
+		page, _ := state.Pages.GetFrontPage()
+		var action func()
+		switch {
+		case triggered(ACTION_QUIT):
+			action = func() { confirmQuit(state) }
+		case triggered(ACTION_HELP):
+			action = func() { showHelp(state, page, keys) }
+		case triggered(ACTION_NEXT):
+			action = nav[page].next
+		case triggered(ACTION_BACK):
+			action = nav[page].back
+		case triggered(ACTION_SEARCH):
+			action = nav[page].search
+		default:
+			return event
+		}
+		if action != nil {
+			action()
+		}
+		return nil
+	}
+}
+
+// return a function that switches to the given page
+func switchTo(state *model.AppState, page string) func() {
+	return func() { state.Pages.SwitchToPage(page) }
+}
+
+// show the page holding the field that the error is about and highlight it. Returns false if the
+// error isn't about a field of the project
+func showRejected(state *model.AppState, nav map[string]*navigation, err error) bool {
+	field, value := errs.FieldOf(err)
+	page, found := fieldPages[field]
+	if !found || nav[page].reject == nil {
+		return false
+	}
+	state.App.SetRoot(state.Pages, true)
+	state.Pages.SwitchToPage(page)
+	nav[page].reject(field, value)
+	return true
+}
+
+// highlight the form's dropdown of the rejected field, the other dropdowns go back to normal
+func rejectFormItem(state *model.AppState, form *tview.Form, labels map[string]string) func(field string, value string) {
+	items := make(map[string]int)
+	for field, label := range labels {
+		items[field] = form.GetFormItemIndex(label)
+	}
+	return func(field string, value string) {
+		for f, idx := range items {
+			dropDown := form.GetFormItem(idx).(*tview.DropDown)
+			dropDown.SetLabel(labels[f])
+			if f == field {
+				dropDown.SetLabel(fmt.Sprintf("%s%s %c", currentTheme.errorText, labels[f], REJECTED_SYMBOL))
+				form.SetFocus(idx)
+				state.App.SetFocus(form)
+			}
+		}
+	}
+}
+
+// use the pre-filled values as the dropdowns' defaults
+func selectDefaults(state *model.AppState, data *model.AppData) {
+	if idx, found := utils.Find(state.SpringBuildTools, func(st model.ValueWithDesc) bool { return st.ID == data.SpringBuildTool }); found {
+		state.DefaultSpringBuildTool = idx
+	}
+	if idx, found := utils.Find(state.Languages, func(l model.Value) bool { return l.ID == data.Language }); found {
+		state.DefaultLanguage = idx
+	}
+	if idx, found := utils.Find(state.SpringVersions, func(v model.Value) bool { return v.ID == data.SpringBootVersion }); found {
+		state.DefaultSpringVersion = idx
+	}
+	if idx, found := utils.Find(state.Packaging, func(p model.Value) bool { return p.ID == data.Packaging }); found {
+		state.DefaultPackaging = idx
+	}
+	if idx, found := utils.Find(state.JavaVersions, func(v model.Value) bool { return v.ID == data.JavaVersion }); found {
+		state.DefaultJavaVersion = idx
+	}
+}
+
+func buildIntroForm(state *model.AppState, data *model.AppData, nav *navigation, opts Options, server *string) *tview.Form {
 	// map values into dropdown options
 	buildTools := utils.Map(state.SpringBuildTools, func(st model.ValueWithDesc) string { return st.Name })
 	languages := utils.Map(state.Languages, func(l model.Value) string { return l.Name })
 	springBootVersions := utils.Map(state.SpringVersions, func(v model.Value) string { return v.Name })
 
-	// build intro form
-	form := tview.NewForm().
-		AddDropDown("Project", buildTools, state.DefaultSpringBuildTool, func(option string, optionIndex int) { data.SpringBuildTool = state.SpringBuildTools[optionIndex].ID }).
-		AddDropDown("Language", languages, state.DefaultLanguage, func(option string, optionIndex int) { data.Language = state.Languages[optionIndex].ID }).
-		AddDropDown("Spring Boot", springBootVersions, state.DefaultSpringVersion, func(option string, optionIndex int) { data.SpringBootVersion = state.SpringVersions[optionIndex].ID }).
-		AddButton("Next", func() { state.Pages.SwitchToPage(PAGE_PRJ_META) }).
-		AddButton("Quit", func() { state.App.Stop() })
-	form.SetBorder(true).SetTitle("Project").SetTitleAlign(tview.AlignLeft)
+	// build intro form, choosing another server closes the wizard to start it again
+	form := tview.NewForm()
+	if servers, current := serverChoices(opts); len(servers) > 1 {
+		names := utils.Map(servers, func(s model.Value) string { return s.Name })
+		form.AddDropDown(i18n.T("field.server"), names, current, func(option string, optionIndex int) {
+			if url := servers[optionIndex].ID; url != client.ServerURL() {
+				*server = url
+				state.App.Stop()
+			}
+		})
+	}
+	form.AddDropDown(i18n.T("field.project"), buildTools, state.DefaultSpringBuildTool, func(option string, optionIndex int) { data.SpringBuildTool = state.SpringBuildTools[optionIndex].ID }).
+		AddDropDown(i18n.T("field.language"), languages, state.DefaultLanguage, func(option string, optionIndex int) { data.Language = state.Languages[optionIndex].ID }).
+		AddDropDown(i18n.T("field.boot"), springBootVersions, state.DefaultSpringVersion, func(option string, optionIndex int) { data.SpringBootVersion = state.SpringVersions[optionIndex].ID }).
+		AddButton(i18n.T("button.next"), nav.next).
+		AddButton(i18n.T("button.quit"), func() { state.App.Stop() })
+	form.SetBorder(true).SetTitle(i18n.T("title.project")).SetTitleAlign(tview.AlignLeft)
+	nav.reject = rejectFormItem(state, form, map[string]string{
+		errs.FIELD_TYPE:         i18n.T("field.project"),
+		errs.FIELD_LANGUAGE:     i18n.T("field.language"),
+		errs.FIELD_BOOT_VERSION: i18n.T("field.boot"),
+	})
 	return form
 }
 
-func buildProjectMetadataForm(state *model.AppState, data *model.AppData) *tview.Form {
-	// map values into dropdown options
+func buildProjectMetadataForm(state *model.AppState, data *model.AppData, nav *navigation, opts Options) *tview.Form {
+	// map values into dropdown options, the installed Java versions are marked
 	packagings := utils.Map(state.Packaging, func(p model.Value) string { return p.Name })
-	javaVersions := utils.Map(state.JavaVersions, func(v model.Value) string { return v.Name })
+	javaVersions := utils.Map(markInstalled(state.JavaVersions, opts.JDKs), func(v model.Value) string { return v.Name })
 
 	// build project metadata form
 	form := tview.NewForm().
-		AddInputField("Group", data.Group, 200, nil, func(text string) { data.Group = text }).
-		AddInputField("Artifact", data.Artifact, 200, nil, func(text string) { data.Artifact = text }).
-		AddInputField("Name", data.Name, 200, nil, func(text string) { data.Name = text }).
-		AddInputField("Description", data.Description, 200, nil, func(text string) { data.Description = text }).
-		AddInputField("Package name", data.Pkg, 200, nil, func(text string) { data.Pkg = text }).
-		AddDropDown("Packaging", packagings, state.DefaultPackaging, func(option string, optionIndex int) { data.Packaging = state.Packaging[optionIndex].ID }).
-		AddDropDown("Java", javaVersions, state.DefaultJavaVersion, func(option string, optionIndex int) { data.JavaVersion = state.JavaVersions[optionIndex].ID }).
-		AddButton("Next", func() { state.Pages.SwitchToPage(PAGE_DEPENDENCIES) }).
-		AddButton("Back", func() { state.Pages.SwitchToPage(PAGE_INTRO) }).
-		AddButton("Quit", func() { state.App.Stop() })
-	form.SetBorder(true).SetTitle("Project Metadata").SetTitleAlign(tview.AlignLeft)
+		AddInputField(i18n.T("field.group"), data.Group, 200, nil, func(text string) { data.Group = text }).
+		AddInputField(i18n.T("field.artifact"), data.Artifact, 200, nil, func(text string) { data.Artifact = text }).
+		AddInputField(i18n.T("field.name"), data.Name, 200, nil, func(text string) { data.Name = text }).
+		AddInputField(i18n.T("field.description"), data.Description, 200, nil, func(text string) { data.Description = text }).
+		AddInputField(i18n.T("field.package"), data.Pkg, 200, nil, func(text string) { data.Pkg = text }).
+		AddDropDown(i18n.T("field.packaging"), packagings, state.DefaultPackaging, func(option string, optionIndex int) { data.Packaging = state.Packaging[optionIndex].ID }).
+		AddDropDown(i18n.T("field.java"), javaVersions, state.DefaultJavaVersion, func(option string, optionIndex int) { data.JavaVersion = state.JavaVersions[optionIndex].ID }).
+		AddButton(i18n.T("button.next"), nav.next).
+		AddButton(i18n.T("button.back"), nav.back).
+		AddButton(i18n.T("button.quit"), func() { state.App.Stop() })
+	form.SetBorder(true).SetTitle(i18n.T("page.metadata")).SetTitleAlign(tview.AlignLeft)
+	nav.reject = rejectFormItem(state, form, map[string]string{
+		errs.FIELD_PACKAGING:    i18n.T("field.packaging"),
+		errs.FIELD_JAVA_VERSION: i18n.T("field.java"),
+	})
 	return form
 }
 
-func buildDependenciesPage(state *model.AppState, data *model.AppData) *tview.Grid {
+func buildDependenciesPage(state *model.AppState, data *model.AppData, nav *navigation, keys keyMap, hist *history.History, opts Options) *tview.Grid {
 	grid := tview.NewGrid().
-		SetRows(-1, -1, -1, 1).SetColumns(0, 0, 0)
+		SetRows(1, -1, -1, -1, 1).SetColumns(0, 0, 0)
+
+	// init search field
+	search := tview.NewInputField().SetLabel(i18n.T("label.search"))
 
 	// init treeview
 	root := tview.NewTreeNode(".")
 	tree := tview.NewTreeView().SetRoot(root).SetCurrentNode(root)
-	tree.SetBorder(true).SetTitle("Dependencies").SetTitleAlign(tview.AlignLeft)
+	tree.SetBorder(true).SetTitle(i18n.T("title.dependencies", keys[ACTION_FAVORITE].name, keys[ACTION_OPEN].name)).SetTitleAlign(tview.AlignLeft)
 
 	// set up description area
-	description := tview.NewTextView().SetText("Description")
-	description.SetBorder(true).SetTitle("Description").SetTitleAlign(tview.AlignLeft)
+	description := tview.NewTextView().SetText(i18n.T("title.description"))
+	description.SetBorder(true).SetTitle(i18n.T("title.description")).SetTitleAlign(tview.AlignLeft)
 
 	// set up selected dependencies area
 	selected := tview.NewList()
-	selected.SetBorder(true).SetTitle("Selected dependencies").SetTitleAlign(tview.AlignLeft)
+	selected.SetBorder(true).SetTitle(i18n.T("title.selected")).SetTitleAlign(tview.AlignLeft)
+	if currentTheme.reverse {
+		selected.SetSelectedStyle(tcell.StyleDefault.Reverse(true))
+	}
+
+	// set up build file preview, it follows the selections and the build tool of the intro page
+	buildFile := newPreview(state.App, data)
+	nav.shown = buildFile.refresh
 
 	// sort category keys to display them in alphabetical order
-	keys := make([]string, 0, len(state.Dependency))
-	for k := range state.Dependency {
-		keys = append(keys, k)
+	categoryKeys := sortedKeys(state.Dependency)
+	byID := make(map[string]model.ValueWithDesc)
+	categoryOf := make(map[string]string)
+	for _, k := range categoryKeys {
+		for _, d := range state.Dependency[k] {
+			if _, found := byID[d.ID]; !found {
+				byID[d.ID] = d
+				categoryOf[d.ID] = k
+			}
+		}
+	}
+
+	// the selection keeps the tree, the list and the project's data in sync
+	sel := newSelection(data, opts.Policy.Requires)
+
+	// a dependency can appear in more categories, all its nodes are kept to update them together
+	nodes := make(map[string][]*tview.TreeNode)
+	newDependencyNode := func(d model.ValueWithDesc) *tview.TreeNode {
+		dependency := tview.NewTreeNode("")
+		dependency.SetReference(d)
+		markSelected(dependency, sel.has(d.ID))
+		if sel.locked(d.ID) {
+			dependency.SetText(dependency.GetText() + " " + i18n.T("dependencies.required"))
+		}
+		nodes[d.ID] = append(nodes[d.ID], dependency)
+		return dependency
 	}
-	sort.Strings(keys)
 
-	// set up tree view
-	for _, k := range keys {
-		v := state.Dependency[k]
-		category := tview.NewTreeNode(k)
-		category.SetSelectable(false)
-		for _, d := range v {
-			dependency := tview.NewTreeNode(d.Name)
-			dependency.SetReference(d)
-			category.AddChild(dependency)
+	// every category keeps all its dependencies, the tree shows only the ones matching the search
+	categories := make([]*category, 0, len(categoryKeys)+2)
+	filter := func() {
+		text := strings.ToLower(search.GetText())
+		root.ClearChildren()
+		for _, c := range categories {
+			matching := make([]*tview.TreeNode, 0, len(c.children))
+			for _, child := range c.children {
+				d := child.GetReference().(model.ValueWithDesc)
+				if strings.Contains(strings.ToLower(d.Name+" "+d.ID+" "+d.Description), text) {
+					matching = append(matching, child)
+				}
+			}
+			c.node.SetChildren(matching)
+			if len(matching) > 0 || (text == "" && c.node.GetText() == i18n.T(CATEGORY_FAVORITES)) {
+				root.AddChild(c.node)
+			}
+		}
+	}
+
+	// favorites and recently used dependencies are shown before the other categories
+	favorites := &category{node: tview.NewTreeNode(i18n.T(CATEGORY_FAVORITES))}
+	favorites.node.SetSelectable(false)
+	fillFavorites := func() {
+		for _, child := range favorites.children {
+			id := child.GetReference().(model.ValueWithDesc).ID
+			if idx, found := utils.Find(nodes[id], func(n *tview.TreeNode) bool { return n == child }); found {
+				nodes[id] = utils.RemoveIndex(nodes[id], idx)
+			}
+		}
+		favorites.children = nil
+		for _, id := range hist.Favorites {
+			if d, found := byID[id]; found {
+				favorites.children = append(favorites.children, newDependencyNode(d))
+			}
+		}
+	}
+	fillFavorites()
+	categories = append(categories, favorites)
+	recent := &category{node: tview.NewTreeNode(i18n.T(CATEGORY_RECENT))}
+	recent.node.SetSelectable(false)
+	for _, id := range hist.Recent {
+		if d, found := byID[id]; found {
+			recent.children = append(recent.children, newDependencyNode(d))
 		}
-		root.AddChild(category)
 	}
+	categories = append(categories, recent)
 
-	// populate description's text area with selected node description
+	// set up tree view, pre-filled dependencies are already selected
+	for _, k := range categoryKeys {
+		c := &category{node: tview.NewTreeNode(k)}
+		c.node.SetSelectable(false)
+		for _, d := range state.Dependency[k] {
+			c.children = append(c.children, newDependencyNode(d))
+		}
+		categories = append(categories, c)
+	}
+	filter()
+
+	// the list shows the selected dependencies with their category
+	// dependency rejected by Spring initializer, highlighted until it's removed
+	rejected := ""
+	fillSelected := func() {
+		current := selected.GetCurrentItem()
+		selected.Clear()
+		for _, d := range data.Dependencies {
+			name, category := tview.Escape(dependencyName(d)), utils.NonNullOrElse(categoryOf[d.ID], i18n.T("dependencies.unknown_category"))
+			if sel.locked(d.ID) {
+				category += ", " + i18n.T("dependencies.required_by_policy")
+			}
+			if d.ID == rejected {
+				name = fmt.Sprintf("%s%s %c", currentTheme.errorText, name, REJECTED_SYMBOL)
+				category += ", " + i18n.T("dependencies.rejected")
+			}
+			selected.AddItem(name, category, SELECTED_SYMBOL, nil)
+		}
+		selected.SetCurrentItem(utils.Min(current, selected.GetItemCount()-1))
+	}
+	fillSelected()
+	nav.reject = func(field string, value string) {
+		rejected = value
+		fillSelected()
+		if value == "" {
+			return
+		}
+		if idx, found := utils.Find(data.Dependencies, func(d model.ValueWithDesc) bool { return d.ID == value }); found {
+			selected.SetCurrentItem(idx)
+		}
+		state.App.SetFocus(selected)
+	}
+	sel.listener = func(d model.ValueWithDesc, isSelected bool) {
+		for _, n := range nodes[d.ID] {
+			markSelected(n, isSelected)
+		}
+		fillSelected()
+		buildFile.refresh()
+	}
+	removeCurrent := func() {
+		if idx := selected.GetCurrentItem(); idx >= 0 && idx < len(data.Dependencies) {
+			sel.remove(data.Dependencies[idx].ID)
+		}
+	}
+	selected.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) { removeCurrent() })
+	selected.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDelete || event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
+			removeCurrent()
+			return nil
+		}
+		return event
+	})
+
+	// populate description's text area with selected node details
+	coordinates := new(coordinatesCache)
 	tree.SetChangedFunc(func(node *tview.TreeNode) {
 		ref, isValueWithDesc := node.GetReference().(model.ValueWithDesc)
 		if isValueWithDesc {
-			description.SetText(ref.Description)
+			byID, err := coordinates.get(data.SpringBootVersion)
+			description.SetText(dependencyDetails(ref, data.SpringBootVersion, byID, err, keys)).ScrollToBeginning()
 		} else {
 			description.Clear()
 		}
@@ -138,82 +790,478 @@ func buildDependenciesPage(state *model.AppState, data *model.AppData) *tview.Gr
 
 	// add or remove dependency from selected list
 	tree.SetSelectedFunc(func(node *tview.TreeNode) {
-		ref, isValueWithDesc := node.GetReference().(model.ValueWithDesc)
-		if !isValueWithDesc {
+		if ref, isValueWithDesc := node.GetReference().(model.ValueWithDesc); isValueWithDesc {
+			sel.toggle(ref)
+		}
+	})
+
+	// pin or unpin the current dependency, open its links
+	openLink := func(ref model.ValueWithDesc, idx int) {
+		if idx >= len(ref.Links) {
 			return
 		}
-		if idx := sort.Search(len(data.Dependencies), func(i int) bool { return data.Dependencies[i].ID == ref.ID }); idx < len(data.Dependencies) {
-			data.Dependencies = utils.RemoveIndex(data.Dependencies, idx)
-			selected.RemoveItem(idx)
-			node.SetColor(tcell.ColorWhite)
-		} else {
-			data.Dependencies = append(data.Dependencies, ref)
-			selected.AddItem(ref.Name, ref.Description, '✓', nil)
-			node.SetColor(tcell.ColorGreen)
+		if err := opener.Open(opts.Opener, linkURL(ref.Links[idx], data.SpringBootVersion)); err != nil {
+			showError(state, err, nil)
 		}
+	}
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		ref, isValueWithDesc := tree.GetCurrentNode().GetReference().(model.ValueWithDesc)
+		if !isValueWithDesc {
+			return event
+		}
+		switch {
+		case keys.matches(ACTION_FAVORITE, event):
+			hist.ToggleFavorite(ref.ID)
+			fillFavorites()
+			filter()
+			if err := hist.Save(); err != nil {
+				showError(state, errors.New(i18n.T("error.favorites", err)), nil)
+			}
+			return nil
+		case keys.matches(ACTION_OPEN, event):
+			openLink(ref, 0)
+			return nil
+		}
+		for n := 1; n <= OPEN_LINK_COUNT; n++ {
+			if keys.matches(openLinkAction(n), event) {
+				openLink(ref, n-1)
+				return nil
+			}
+		}
+		return event
 	})
 
 	// add buttons
-	buttonGrid := tview.NewGrid().SetRows(0).SetColumns(0, 0, 0).SetGap(0, 1)
-	next := tview.NewButton("Next").SetSelectedFunc(func() { state.Pages.SwitchToPage(PAGE_PRJ_PATH) })
-	back := tview.NewButton("Back").SetSelectedFunc(func() { state.Pages.SwitchToPage(PAGE_PRJ_META) })
-	quit := tview.NewButton("Quit").SetSelectedFunc(func() { state.App.Stop() })
+	buttonGrid := tview.NewGrid().SetRows(0).SetColumns(0, 0, 0, 0, 0).SetGap(0, 1)
+	next := tview.NewButton(i18n.T("button.next")).SetSelectedFunc(nav.next)
+	clearAll := tview.NewButton(i18n.T("button.clear_all")).SetSelectedFunc(sel.clear)
+	modules := tview.NewButton(i18n.T("button.modules")).SetSelectedFunc(switchTo(state, PAGE_MODULES))
+	back := tview.NewButton(i18n.T("button.back")).SetSelectedFunc(nav.back)
+	quit := tview.NewButton(i18n.T("button.quit")).SetSelectedFunc(func() { state.App.Stop() })
 	buttonGrid.AddItem(next, 1, 0, 1, 1, 0, 0, false)
-	buttonGrid.AddItem(back, 1, 1, 1, 1, 0, 0, false)
-	buttonGrid.AddItem(quit, 1, 2, 1, 1, 0, 0, false)
+	buttonGrid.AddItem(modules, 1, 1, 1, 1, 0, 0, false)
+	buttonGrid.AddItem(clearAll, 1, 2, 1, 1, 0, 0, false)
+	buttonGrid.AddItem(back, 1, 3, 1, 1, 0, 0, false)
+	buttonGrid.AddItem(quit, 1, 4, 1, 1, 0, 0, false)
+
+	// filter the tree while typing, Enter and Esc go back to the tree
+	search.SetChangedFunc(func(text string) { filter() })
+	search.SetDoneFunc(func(key tcell.Key) { state.App.SetFocus(tree) })
+	nav.search = func() { state.App.SetFocus(search) }
 
 	// set up focus handling
-	primitives := []tview.Primitive{tree, selected, next, back, quit}
+	var layout func()
+	primitives := []tview.Primitive{search, tree, selected, next, modules, clearAll, back, quit}
 	grid.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyTab {
+		switch {
+		case keys.matches(ACTION_FOCUS_NEXT, event):
 			cycleFocus(state.App, primitives, false)
+		case keys.matches(ACTION_FOCUS_PREV, event):
+			cycleFocus(state.App, primitives, true)
+		case keys.matches(ACTION_PREVIEW, event) && !search.HasFocus():
+			buildFile.toggle()
+			layout()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	// add items to the grid, the preview takes the right column when it's shown
+	layout = func() {
+		grid.Clear()
+		grid.AddItem(search, 0, 0, 1, 1, 0, 0, false)
+		grid.AddItem(tree, 1, 0, 3, 1, 0, 10, true)
+		grid.AddItem(buttonGrid, 4, 0, 1, 1, 0, 0, false)
+		if buildFile.visible {
+			grid.AddItem(selected, 0, 1, 3, 1, 0, 0, false)
+			grid.AddItem(description, 3, 1, 1, 1, 0, 50, false)
+			grid.AddItem(buildFile.view, 0, 2, 4, 1, 0, 0, false)
+		} else {
+			grid.AddItem(selected, 0, 1, 3, 2, 0, 0, false)
+			grid.AddItem(description, 3, 1, 1, 2, 0, 50, false)
+		}
+	}
+	layout()
+	return grid
+}
+
+func buildModulesPage(state *model.AppState, data *model.AppData, nav *navigation, keys keyMap) *tview.Grid {
+	grid := tview.NewGrid().
+		SetRows(0, 2).SetColumns(0, 0)
+
+	// set up modules area, each module shows its dependencies
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(i18n.T("page.modules")).SetTitleAlign(tview.AlignLeft)
+	dependencyNames := func(m model.Module) string {
+		return strings.Join(utils.Map(m.Dependencies, func(d model.ValueWithDesc) string { return d.Name }), ", ")
+	}
+	for _, m := range data.Modules {
+		list.AddItem(m.Name, dependencyNames(m), 0, nil)
+	}
+
+	// remove the current module
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDelete && list.GetItemCount() > 0 {
+			idx := list.GetCurrentItem()
+			data.Modules = utils.RemoveIndex(data.Modules, idx)
+			list.RemoveItem(idx)
+			return nil
 		}
 		return event
 	})
 
-	// add items to the grid
-	grid.AddItem(tree, 0, 0, 3, 1, 0, 10, true)
-	grid.AddItem(selected, 0, 1, 2, 2, 0, 0, false)
-	grid.AddItem(description, 2, 1, 1, 2, 0, 50, false)
-	grid.AddItem(buttonGrid, 3, 0, 1, 1, 0, 0, false)
+	// add a module with the dependencies currently selected
+	var name string
+	form := tview.NewForm()
+	form.AddInputField(i18n.T("field.module"), "", 50, nil, func(text string) { name = text }).
+		AddButton(i18n.T("button.add"), func() {
+			if name == "" {
+				showError(state, errors.New(i18n.T("error.module_name")), nil)
+				return
+			}
+			if _, found := utils.Find(data.Modules, func(m model.Module) bool { return m.Name == name }); found {
+				showError(state, errors.New(i18n.T("error.module_exists", name)), nil)
+				return
+			}
+			module := model.Module{Name: name, Dependencies: append([]model.ValueWithDesc(nil), data.Dependencies...)}
+			data.Modules = append(data.Modules, module)
+			list.AddItem(module.Name, dependencyNames(module), 0, nil)
+			form.GetFormItem(0).(*tview.InputField).SetText("")
+		}).
+		AddButton(i18n.T("button.done"), nav.next)
+	form.SetBorder(true).SetTitle(i18n.T("title.add_module")).SetTitleAlign(tview.AlignLeft)
+
+	// set up help area
+	help := tview.NewTextView().SetText(i18n.T("modules.help"))
+
+	// set up focus handling
+	grid.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if (keys.matches(ACTION_FOCUS_NEXT, event) || keys.matches(ACTION_FOCUS_PREV, event)) && list.HasFocus() {
+			state.App.SetFocus(form)
+			return nil
+		}
+		return event
+	})
+	form.SetCancelFunc(func() { state.App.SetFocus(list) })
+
+	grid.AddItem(form, 0, 0, 1, 1, 0, 0, true)
+	grid.AddItem(list, 0, 1, 1, 1, 0, 0, false)
+	grid.AddItem(help, 1, 0, 1, 2, 0, 0, false)
 	return grid
 }
 
-func buildProjectPathPage(state *model.AppState, data *model.AppData) *tview.Form {
-	// get user's home dir
-	initialDir, err := os.UserHomeDir()
-	if err != nil {
-		showError(state, fmt.Errorf("can't get user's home dir: %w", err), nil)
-		initialDir = ""
+func buildProjectPathPage(state *model.AppState, data *model.AppData, pages map[string]*navigation, keys keyMap, hist *history.History, opts Options, project *generatedProject) *tview.Grid {
+	nav := pages[PAGE_PRJ_PATH]
+	// start from the configured path or from the user's home dir
+	initialDir := data.Path
+	if initialDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			showError(state, errors.New(i18n.T("error.home", err)), nil)
+		}
+		initialDir = home
 	}
 	data.Path = initialDir
 
-	// build project path form
-	form := tview.NewForm().
-		AddInputField("Project path", initialDir, 200, nil, func(text string) { data.Path = text }).
-		AddButton("Next", func() {
-			if err := client.Generate(data); err != nil {
-				// handle project generation error
+	// the path is expanded while typing, the resulting project directory and its problems are shown below
+	input := tview.NewInputField().SetLabel(i18n.T("label.path")).SetText(initialDir)
+	resolved := tview.NewTextView().SetDynamicColors(true)
+	browse := newBrowser()
+	checks := new(debouncer)
+	update := func() {
+		data.Path = utils.ExpandPath(input.GetText())
+		text := i18n.T("path.target", tview.Escape(filepath.Join(data.Path, data.Artifact)))
+		resolved.SetText(text)
+		// the checks touch the file system, they wait for the typing to pause
+		dir, artifact, javaVersion := data.Path, data.Artifact, data.JavaVersion
+		checks.call(PATH_CHECK_DELAY, func(latest func() bool) {
+			warnings := checkProjectPath(dir, artifact)
+			if w := checkJavaVersion(javaVersion, opts.JDKs); w != "" {
+				warnings = append(warnings, w)
+			}
+			for _, w := range warnings {
+				text += fmt.Sprintf("\n%s%s", currentTheme.errorText, tview.Escape(w))
+			}
+			state.App.QueueUpdateDraw(func() {
+				if latest() {
+					resolved.SetText(text)
+				}
+			})
+		})
+	}
+	input.SetChangedFunc(func(text string) {
+		update()
+		browse.moveTo(data.Path)
+	})
+	browse.changed = func(dir string) {
+		if dir != filepath.Clean(data.Path) {
+			input.SetText(dir)
+		}
+	}
+	browse.moveTo(data.Path)
+	nav.shown = update
+
+	// generate the project, after a confirmation if it's likely to fail or to overwrite files
+	create := func() {
+		// the fields rejected by the last attempt are no longer highlighted
+		for _, n := range pages {
+			if n.reject != nil {
+				n.reject("", "")
+			}
+		}
+		if err := generate(data, opts); err != nil {
+			// handle project generation error, going back to the rejected field if there's one
+			showError(state, err, func(buttonIndex int, buttonLabel string) {
+				if !showRejected(state, pages, err) {
+					state.App.SetRoot(state.Pages, true).SetFocus(state.Pages)
+				}
+			})
+		} else {
+			// remember the chosen dependencies, show info message and quit, opening the project
+			// once the wizard is closed if it's asked
+			project.dir = filepath.Join(data.Path, data.Artifact)
+			message := i18n.T("info.created", project.dir)
+			hist.Record(dependencyIDs(data))
+			if err := hist.Save(); err != nil {
+				message += "\n\n" + i18n.T("error.history", err)
+			}
+			buttons := []string{i18n.T("button.ok")}
+			if !opts.Open {
+				buttons = append(buttons, i18n.T("button.open"))
+			}
+			showModal(state, message, currentTheme.info, buttons, func(buttonIndex int, buttonLabel string) {
+				project.open = opts.Open || buttonIndex == 1
+				state.App.Stop()
+			})
+		}
+	}
+	nav.next = func() {
+		if err := opts.Policy.Check(data); err != nil {
+			showError(state, err, nil)
+			return
+		}
+		warnings := checkProjectPath(data.Path, data.Artifact)
+		if len(warnings) == 0 {
+			create()
+			return
+		}
+		showModal(state, strings.Join(warnings, "\n")+"\n\n"+i18n.T("path.confirm"), currentTheme.error, []string{i18n.T("button.generate"), i18n.T("button.cancel")}, func(buttonIndex int, buttonLabel string) {
+			state.App.SetRoot(state.Pages, true).SetFocus(state.Pages)
+			if buttonIndex == 0 {
+				create()
+			}
+		})
+	}
+
+	// add buttons
+	buttonGrid := tview.NewGrid().SetRows(0).SetColumns(0, 0, 0, 0).SetGap(0, 1)
+	next := tview.NewButton(i18n.T("button.next")).SetSelectedFunc(nav.next)
+	newFolder := tview.NewButton(i18n.T("button.new_folder")).SetSelectedFunc(func() {
+		askText(state, i18n.T("title.new_folder", browse.dir), i18n.T("field.folder"), func(name string) {
+			if err := browse.mkdir(name); err != nil {
 				showError(state, err, nil)
-			} else {
-				// show info message and quit
-				showInfo(state, fmt.Sprintf("Project created in \"%s\"", path.Join(data.Path, data.Artifact)), func(buttonIndex int, buttonLabel string) { state.App.Stop() })
 			}
-		}).
-		AddButton("Back", func() { state.Pages.SwitchToPage(PAGE_DEPENDENCIES) }).
-		AddButton("Quit", func() { state.App.Stop() })
-	form.SetBorder(true).SetTitle("Project").SetTitleAlign(tview.AlignLeft)
-	return form
+		})
+	})
+	back := tview.NewButton(i18n.T("button.back")).SetSelectedFunc(nav.back)
+	quit := tview.NewButton(i18n.T("button.quit")).SetSelectedFunc(func() { state.App.Stop() })
+	buttonGrid.AddItem(next, 0, 0, 1, 1, 0, 0, false)
+	buttonGrid.AddItem(newFolder, 0, 1, 1, 1, 0, 0, false)
+	buttonGrid.AddItem(back, 0, 2, 1, 1, 0, 0, false)
+	buttonGrid.AddItem(quit, 0, 3, 1, 1, 0, 0, false)
+
+	// set up focus handling
+	primitives := []tview.Primitive{input, browse.tree, next, newFolder, back, quit}
+	// summary of the policy that was applied
+	summary := opts.Policy.Summary()
+	policyView := tview.NewTextView().SetText(strings.Join(summary, "\n"))
+
+	grid := tview.NewGrid().SetRows(1, 3, len(summary), 0, 1).SetColumns(0)
+	grid.SetBorder(true).SetTitle(i18n.T("title.project")).SetTitleAlign(tview.AlignLeft)
+	grid.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case keys.matches(ACTION_FOCUS_NEXT, event):
+			cycleFocus(state.App, primitives, false)
+		case keys.matches(ACTION_FOCUS_PREV, event):
+			cycleFocus(state.App, primitives, true)
+		default:
+			return event
+		}
+		return nil
+	})
+	grid.AddItem(input, 0, 0, 1, 1, 0, 0, true)
+	grid.AddItem(resolved, 1, 0, 1, 1, 0, 0, false)
+	grid.AddItem(policyView, 2, 0, 1, 1, 0, 0, false)
+	grid.AddItem(browse.tree, 3, 0, 1, 1, 0, 0, false)
+	grid.AddItem(buttonGrid, 4, 0, 1, 1, 0, 0, false)
+	return grid
+}
+
+// coordinates of the server's dependencies by ID, got for the chosen Spring Boot version when a
+// dependency is first described
+type coordinatesCache struct {
+	bootVersion string
+	loaded      bool
+	byID        map[string]model.Coordinates
+	err         error
+}
+
+func (c *coordinatesCache) get(bootVersion string) (map[string]model.Coordinates, error) {
+	if !c.loaded || c.bootVersion != bootVersion {
+		c.byID, c.err = client.GetDependencyCoordinates(bootVersion)
+		c.bootVersion, c.loaded = bootVersion, true
+		if c.err != nil {
+			logging.Warn("can't get the dependencies' coordinates", "bootVersion", bootVersion, "error", c.err)
+		}
+	}
+	return c.byID, c.err
+}
+
+// text describing a dependency: its description, compatibility, coordinates and links with the
+// keys opening them. The coordinates of the server's dependencies are given by ID, with the error
+// that prevented getting them
+func dependencyDetails(d model.ValueWithDesc, bootVersion string, coordinates map[string]model.Coordinates, coordinatesErr error, keys keyMap) string {
+	var b strings.Builder
+	b.WriteString(d.Description)
+	if d.VersionRange != "" {
+		fmt.Fprintf(&b, "\n\n%s", i18n.T("details.boot", d.VersionRange))
+	}
+	if d.Source != "" {
+		fmt.Fprintf(&b, "\n%s", i18n.T("details.server", d.Source))
+	}
+	c, found := coordinates[d.ID]
+	if d.Custom != nil {
+		c, found = d.Custom.Coordinates, true
+	}
+	if found {
+		gav := c.GroupId + ":" + c.ArtifactId
+		if c.Version != "" {
+			gav += ":" + c.Version
+		}
+		fmt.Fprintf(&b, "\n%s", i18n.T("details.coordinates", gav))
+	} else if coordinatesErr != nil {
+		fmt.Fprintf(&b, "\n%s", i18n.T("details.coordinates_unavailable", coordinatesErr))
+	}
+	if len(d.Links) > 0 {
+		fmt.Fprintf(&b, "\n\n%s", i18n.T("details.links", keys[ACTION_OPEN].name))
+		for i, l := range d.Links {
+			// the links are labeled with the keys opening them
+			label := fmt.Sprint(i + 1)
+			if binding, found := keys[openLinkAction(i+1)]; found {
+				label = binding.name
+			}
+			fmt.Fprintf(&b, "\n%s. %s: %s", label, l.Rel, utils.NonNullOrElse(l.Title, linkURL(l, bootVersion)))
+		}
+	}
+	return b.String()
+}
+
+// name of a dependency followed by the server it comes from, if it's not the one in use. The
+// server is between parentheses since the brackets are color tags
+func dependencyName(d model.ValueWithDesc) string {
+	if d.Source == "" {
+		return d.Name
+	}
+	return fmt.Sprintf("%s (%s)", d.Name, d.Source)
+}
+
+// URL of a link, templated links are resolved with the chosen Spring Boot version
+func linkURL(l model.Link, bootVersion string) string {
+	if l.Templated {
+		return strings.ReplaceAll(l.Href, "{bootVersion}", bootVersion)
+	}
+	return l.Href
+}
+
+// IDs of the dependencies of the project and of all its modules
+func dependencyIDs(data *model.AppData) []string {
+	ids := utils.Map(data.Dependencies, func(d model.ValueWithDesc) string { return d.ID })
+	for _, m := range data.Modules {
+		for _, d := range m.Dependencies {
+			if !utils.Contains(ids, d.ID) {
+				ids = append(ids, d.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// ask for a confirmation before quitting
+func confirmQuit(state *model.AppState) {
+	showModal(state, i18n.T("quit.confirm"), currentTheme.info, []string{i18n.T("button.quit"), i18n.T("button.cancel")}, func(buttonIndex int, buttonLabel string) {
+		if buttonIndex == 0 {
+			state.App.Stop()
+		} else {
+			state.App.SetRoot(state.Pages, true).SetFocus(state.Pages)
+		}
+	})
+}
+
+// show the keys of the given page and the global ones, Enter or Esc closes the help
+func showHelp(state *model.AppState, page string, keys keyMap) {
+	lines := append(utils.Map(pageHelp[page], func(key string) string { return i18n.T(key) }), keys.help()...)
+	text := tview.NewTextView().SetText(strings.Join(lines, "\n"))
+	text.SetBorder(true).SetTitle(i18n.T("title.help", i18n.T(pageTitles[page]))).SetTitleAlign(tview.AlignLeft)
+	text.SetDoneFunc(func(key tcell.Key) { state.App.SetRoot(state.Pages, true).SetFocus(state.Pages) })
+
+	// center the help on the screen
+	width := 0
+	for _, l := range lines {
+		width = utils.Max(width, len(l))
+	}
+	grid := tview.NewGrid().SetRows(0, len(lines)+2, 0).SetColumns(0, width+4, 0).
+		AddItem(text, 1, 1, 1, 1, 0, 0, true)
+	state.App.SetRoot(grid, true).SetFocus(text)
+}
+
+// ask for a text in a small form, the handler is called with the text unless the form is cancelled
+func askText(state *model.AppState, title string, label string, handler func(text string)) {
+	text := ""
+	form := tview.NewForm()
+	done := func(confirmed bool) {
+		state.App.SetRoot(state.Pages, true).SetFocus(state.Pages)
+		if confirmed {
+			handler(text)
+		}
+	}
+	form.AddInputField(label, "", 40, nil, func(t string) { text = t }).
+		AddButton(i18n.T("button.ok"), func() { done(true) }).
+		AddButton(i18n.T("button.cancel"), func() { done(false) }).
+		SetCancelFunc(func() { done(false) })
+	form.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignLeft)
+
+	// center the form on the screen
+	grid := tview.NewGrid().SetRows(0, 7, 0).SetColumns(0, 60, 0).
+		AddItem(form, 1, 1, 1, 1, 0, 0, true)
+	state.App.SetRoot(grid, true).SetFocus(form)
 }
 
 // helper that shows an info modal
 func showInfo(state *model.AppState, message string, handler func(buttonIndex int, buttonLabel string)) {
-	showModal(state, message, tcell.ColorBlue, []string{"Ok"}, handler)
+	showModal(state, message, currentTheme.info, []string{i18n.T("button.ok")}, handler)
 }
 
 // helper that shows an error modal
 func showError(state *model.AppState, err error, handler func(buttonIndex int, buttonLabel string)) {
-	showModal(state, err.Error(), tcell.ColorRed, []string{"Ok"}, handler)
+	logging.Warn("error shown", "error", err)
+	showModal(state, err.Error(), currentTheme.error, []string{i18n.T("button.ok")}, handler)
+}
+
+// show whether the dependency of the node is selected, with both a symbol and a color
+func markSelected(node *tview.TreeNode, isSelected bool) {
+	d := node.GetReference().(model.ValueWithDesc)
+	if isSelected {
+		node.SetText(fmt.Sprintf("%c %s", SELECTED_SYMBOL, dependencyName(d))).SetColor(currentTheme.selected)
+	} else {
+		node.SetText("  " + dependencyName(d)).SetColor(currentTheme.styles.PrimaryTextColor)
+	}
+}
+
+// category of the dependency tree with all its dependencies, even the ones hidden by the search
+type category struct {
+	node     *tview.TreeNode
+	children []*tview.TreeNode
 }
 
 // helper that shows a modal. The handler function is used to set the behaviour when one of the buttons is chosen
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"os"
+	"tacher/src/config"
+	"tacher/src/model"
+	"testing"
+)
+
+func TestLayered(t *testing.T) {
+	// the configuration's layers, up to the environment, only set the Java version
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(config.EnvName(config.JAVA_VERSION), "20")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		name string
+		like *model.AppData
+		flag string
+		want string
+	}{
+		{"environment", nil, "", "20"},
+		{"--like over the environment", &model.AppData{JavaVersion: "17"}, "", "17"},
+		{"--like without the Java version", &model.AppData{Packaging: "war"}, "", "20"},
+		{"flag over --like", &model.AppData{JavaVersion: "17"}, "21", "21"},
+	}
+	for _, test := range tests {
+		cfg, err := config.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.flag != "" {
+			cfg.Set(config.JAVA_VERSION, test.flag, config.ORIGIN_FLAG+"--java")
+		}
+		data := layered(cfg.AppData(false), test.like, cfg.AppData(true))
+		if data.JavaVersion != test.want {
+			t.Errorf("%s: Java version = %q, want %q", test.name, data.JavaVersion, test.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// names of the built-in themes
+const THEME_DARK = "dark"
+const THEME_LIGHT = "light"
+const THEME_HIGH_CONTRAST = "high-contrast"
+const THEME_NO_COLOR = "no-color"
+const DEFAULT_THEME = THEME_DARK
+
+// symbol prefixed to the selected dependencies, so that the selection is visible without colors
+const SELECTED_SYMBOL = '✓'
+
+// symbol following the fields rejected by Spring initializer
+const REJECTED_SYMBOL = '✗'
+
+// colors of the UI
+type theme struct {
+	// colors of tview's primitives
+	styles tview.Theme
+	// text color of the selected dependencies
+	selected tcell.Color
+	// backgrounds of the info and error modals
+	info  tcell.Color
+	error tcell.Color
+	// style tags of the preview's changed lines and errors, with the tags resetting them
+	highlight      string
+	highlightReset string
+	errorText      string
+	// current items of the lists are shown in reverse video, colors can't tell them apart
+	reverse bool
+}
+
+var themes = map[string]theme{
+	THEME_DARK: {
+		styles:         tview.Styles,
+		selected:       tcell.ColorGreen,
+		info:           tcell.ColorBlue,
+		error:          tcell.ColorRed,
+		highlight:      "[black:yellow]",
+		highlightReset: "[-:-]",
+		errorText:      "[red]",
+	},
+	THEME_LIGHT: {
+		styles: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorWhite,
+			ContrastBackgroundColor:     tcell.ColorLightSteelBlue,
+			MoreContrastBackgroundColor: tcell.ColorSilver,
+			BorderColor:                 tcell.ColorBlack,
+			TitleColor:                  tcell.ColorNavy,
+			GraphicsColor:               tcell.ColorBlack,
+			PrimaryTextColor:            tcell.ColorBlack,
+			SecondaryTextColor:          tcell.ColorMaroon,
+			TertiaryTextColor:           tcell.ColorDarkGreen,
+			InverseTextColor:            tcell.ColorWhite,
+			ContrastSecondaryTextColor:  tcell.ColorNavy,
+		},
+		selected:       tcell.ColorDarkGreen,
+		info:           tcell.ColorLightSkyBlue,
+		error:          tcell.ColorLightPink,
+		highlight:      "[black:lightgoldenrodyellow]",
+		highlightReset: "[-:-]",
+		errorText:      "[maroon]",
+	},
+	THEME_HIGH_CONTRAST: {
+		styles: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorBlack,
+			ContrastBackgroundColor:     tcell.ColorNavy,
+			MoreContrastBackgroundColor: tcell.ColorWhite,
+			BorderColor:                 tcell.ColorYellow,
+			TitleColor:                  tcell.ColorYellow,
+			GraphicsColor:               tcell.ColorWhite,
+			PrimaryTextColor:            tcell.ColorWhite,
+			SecondaryTextColor:          tcell.ColorYellow,
+			TertiaryTextColor:           tcell.ColorAqua,
+			InverseTextColor:            tcell.ColorBlack,
+			ContrastSecondaryTextColor:  tcell.ColorYellow,
+		},
+		selected:       tcell.ColorYellow,
+		info:           tcell.ColorNavy,
+		error:          tcell.ColorMaroon,
+		highlight:      "[black:white]",
+		highlightReset: "[-:-]",
+		errorText:      "[yellow]",
+	},
+	THEME_NO_COLOR: {
+		styles: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorDefault,
+			ContrastBackgroundColor:     tcell.ColorDefault,
+			MoreContrastBackgroundColor: tcell.ColorDefault,
+			BorderColor:                 tcell.ColorDefault,
+			TitleColor:                  tcell.ColorDefault,
+			GraphicsColor:               tcell.ColorDefault,
+			PrimaryTextColor:            tcell.ColorDefault,
+			SecondaryTextColor:          tcell.ColorDefault,
+			TertiaryTextColor:           tcell.ColorDefault,
+			InverseTextColor:            tcell.ColorDefault,
+			ContrastSecondaryTextColor:  tcell.ColorDefault,
+		},
+		selected:       tcell.ColorDefault,
+		info:           tcell.ColorDefault,
+		error:          tcell.ColorDefault,
+		highlight:      "[::r]",
+		highlightReset: "[::-]",
+		errorText:      "",
+		reverse:        true,
+	},
+}
+
+// theme of the running UI
+var currentTheme = themes[DEFAULT_THEME]
+
+// set the theme with the given name, the default one if the name is empty. NO_COLOR disables
+// the colors whatever the chosen theme is, see https://no-color.org
+func setTheme(name string) error {
+	if os.Getenv("NO_COLOR") != "" {
+		name = THEME_NO_COLOR
+	}
+	if name == "" {
+		name = DEFAULT_THEME
+	}
+	t, found := themes[name]
+	if !found {
+		return fmt.Errorf("unknown theme %s, available themes are %v", name, ThemeNames())
+	}
+	currentTheme = t
+	tview.Styles = t.styles
+	return nil
+}
+
+// names of the available themes, sorted
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
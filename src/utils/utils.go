@@ -1,6 +1,11 @@
 package utils
 
-import "io"
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 // if 'object' is null then return 'def'
 func NonNullOrElse[T comparable](object T, def T) T {
@@ -31,6 +36,28 @@ func Find[T any](list []T, compareFunction func(elem T) bool) (int, bool) {
 	return -1, false
 }
 
+// return the greater of two numbers
+func Max[T int | int64 | float64](a T, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// return the lesser of two numbers
+func Min[T int | int64 | float64](a T, b T) T {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// check if the slice contains the given element
+func Contains[T comparable](list []T, elem T) bool {
+	_, found := Find(list, func(e T) bool { return e == elem })
+	return found
+}
+
 // remove the i-th from the slice, the following elements are moved left
 func RemoveIndex[T interface{}](s []T, index int) []T {
 	return append(s[:index], s[index+1:]...)
@@ -43,3 +70,26 @@ func CheckClose(reader io.Closer) error {
 	}
 	return nil
 }
+
+// directory of tacher's configuration, $XDG_CONFIG_HOME/tacher or ~/.config/tacher
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "tacher"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "tacher"), nil
+}
+
+// expand a leading '~' to the user's home directory and the environment variables in the path
+func ExpandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	return path
+}
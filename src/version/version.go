@@ -0,0 +1,138 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// qualifiers of the versions published by Spring, from the least to the most mature
+var qualifiers = map[string]int{
+	"M":              1,
+	"RC":             2,
+	"SNAPSHOT":       3,
+	"BUILD-SNAPSHOT": 3,
+	"":               4,
+	"RELEASE":        4,
+}
+
+// versions look like '3.0.0', '3.0.1-SNAPSHOT', '3.1.0-M1' or '2.5.14.RELEASE'
+var versionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:[.-]([A-Za-z-]+?)(\d*))?$`)
+
+// version of Spring Boot or of a dependency
+type Version struct {
+	Major     int
+	Minor     int
+	Patch     int
+	Qualifier string
+	Number    int
+}
+
+// parse a version
+func Parse(text string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid version %s", text)
+	}
+	if _, known := qualifiers[strings.ToUpper(m[4])]; !known {
+		return Version{}, fmt.Errorf("invalid qualifier in version %s", text)
+	}
+	v := Version{Qualifier: strings.ToUpper(m[4])}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	v.Number, _ = strconv.Atoi(m[5])
+	return v, nil
+}
+
+// check if the version is a milestone, a release candidate or a snapshot
+func (v Version) IsPreRelease() bool {
+	return qualifiers[v.Qualifier] < qualifiers[""]
+}
+
+// compare two versions, returns a negative number if v comes before o, a positive one if v
+// comes after o and zero if they are equal
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch, qualifiers[v.Qualifier] - qualifiers[o.Qualifier], v.Number - o.Number} {
+		if d != 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// range of versions in Spring initializer's format: '[2.7.0,3.0.0-M1)' includes 2.7.0 and
+// excludes 3.0.0-M1, while a single version like '2.7.0' has no upper bound
+type Range struct {
+	Lower          *Version
+	LowerInclusive bool
+	Upper          *Version
+	UpperInclusive bool
+}
+
+// parse a version range, an empty text matches every version
+func ParseRange(text string) (Range, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Range{}, nil
+	}
+	if !strings.ContainsAny(text[:1], "[(") {
+		lower, err := Parse(text)
+		return Range{Lower: &lower, LowerInclusive: true}, err
+	}
+
+	if len(text) < 2 || !strings.ContainsAny(text[len(text)-1:], "])") {
+		return Range{}, fmt.Errorf("invalid version range %s", text)
+	}
+	bounds := strings.Split(text[1:len(text)-1], ",")
+	if len(bounds) != 2 {
+		return Range{}, fmt.Errorf("invalid version range %s", text)
+	}
+	r := Range{LowerInclusive: text[0] == '[', UpperInclusive: text[len(text)-1] == ']'}
+	if b := strings.TrimSpace(bounds[0]); b != "" {
+		lower, err := Parse(b)
+		if err != nil {
+			return r, err
+		}
+		r.Lower = &lower
+	}
+	if b := strings.TrimSpace(bounds[1]); b != "" {
+		upper, err := Parse(b)
+		if err != nil {
+			return r, err
+		}
+		r.Upper = &upper
+	}
+	return r, nil
+}
+
+// check if the version is in the range
+func (r Range) Contains(v Version) bool {
+	if r.Lower != nil {
+		c := v.Compare(*r.Lower)
+		if c < 0 || (c == 0 && !r.LowerInclusive) {
+			return false
+		}
+	}
+	if r.Upper != nil {
+		c := v.Compare(*r.Upper)
+		if c > 0 || (c == 0 && !r.UpperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// check if the version is in the range, both given as text
+func InRange(versionText string, rangeText string) (bool, error) {
+	r, err := ParseRange(rangeText)
+	if err != nil {
+		return false, err
+	}
+	v, err := Parse(versionText)
+	if err != nil {
+		return false, err
+	}
+	return r.Contains(v), nil
+}
@@ -0,0 +1,40 @@
+package version
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		text    string
+		version string
+		in      bool
+	}{
+		{"", "3.0.0", true},
+		{"2.7.0", "2.7.0", true},
+		{"2.7.0", "2.6.9", false},
+		{"[2.7.0,3.0.0-M1)", "2.7.5", true},
+		{"[2.7.0,3.0.0-M1)", "3.0.0-M1", false},
+		{"[2.7.0,3.0.0-M1)", "2.7.0", true},
+		{"(2.7.0,3.0.0]", "2.7.0", false},
+		{"(2.7.0,3.0.0]", "3.0.0", true},
+		{"[3.0.0-M1,)", "3.0.0", true},
+		{"[3.0.0-M1,)", "3.0.0-SNAPSHOT", true},
+		{"[3.0.0,)", "3.0.0-RC1", false},
+		{"[2.5.0.RELEASE,2.6.0)", "2.5.14.RELEASE", true},
+	}
+	for _, test := range tests {
+		in, err := InRange(test.version, test.text)
+		if err != nil {
+			t.Errorf("InRange(%q, %q) failed: %v", test.version, test.text, err)
+		} else if in != test.in {
+			t.Errorf("InRange(%q, %q) = %v, want %v", test.version, test.text, in, test.in)
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	for _, text := range []string{"[", "(", "[2.7.0", "[2.7.0)", "[2.7.0,3.0.0,4.0.0)", "[a,b)", "2.7.x"} {
+		if _, err := ParseRange(text); err == nil {
+			t.Errorf("ParseRange(%q) succeeded, want an error", text)
+		}
+	}
+}